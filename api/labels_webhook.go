@@ -0,0 +1,165 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"context"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+	"github.com/openshift-kni/node-label-operator/pkg"
+)
+
+// +kubebuilder:webhook:path=/validate-v1beta1-labels,mutating=false,failurePolicy=fail,sideEffects=None,groups=node-labels.openshift.io,resources=labels,verbs=create;update,versions=v1beta1,name=vlabels.kb.io,admissionReviewVersions={v1,v1beta1}
+
+// LabelsValidator validates Labels
+type LabelsValidator struct {
+	decoder *admission.Decoder
+}
+
+func (v *LabelsValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	labels := &v1beta1.Labels{}
+	if err := v.decoder.Decode(req, labels); err != nil {
+		log.Error(err, "Failed to decode Labels")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Update {
+		// don't block metadata updates, e.g. finalizer removal, of existing invalid Labels
+		labelsOld := &v1beta1.Labels{}
+		if err := v.decoder.DecodeRaw(req.OldObject, labelsOld); err != nil {
+			log.Error(err, "Failed to decode old Labels")
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if equality.Semantic.DeepEqual(labelsOld.Spec, labels.Spec) {
+			return admission.Allowed("spec unchanged")
+		}
+	}
+
+	if err := pkg.ValidateLabels(*labels); err != nil {
+		log.Info("Rejecting invalid Labels", "labels", labels.Name, "reason", err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("valid Labels")
+}
+
+// InjectDecoder injects the decoder.
+func (v *LabelsValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *LabelsValidator) SetupWebhookWithManager(mgr ctrl.Manager) {
+	hookServer := mgr.GetWebhookServer()
+	hookServer.Register("/validate-v1beta1-labels", &webhook.Admission{Handler: &LabelsValidator{}})
+}
+
+// +kubebuilder:webhook:path=/validate-v1beta1-ownedlabels,mutating=false,failurePolicy=fail,sideEffects=None,groups=node-labels.openshift.io,resources=ownedlabels,verbs=create;update,versions=v1beta1,name=vownedlabels.kb.io,admissionReviewVersions={v1,v1beta1}
+
+// OwnedLabelsValidator validates OwnedLabels
+type OwnedLabelsValidator struct {
+	decoder *admission.Decoder
+}
+
+func (v *OwnedLabelsValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	ownedLabels := &v1beta1.OwnedLabels{}
+	if err := v.decoder.Decode(req, ownedLabels); err != nil {
+		log.Error(err, "Failed to decode OwnedLabels")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Update {
+		// don't block metadata updates of existing invalid OwnedLabels
+		ownedLabelsOld := &v1beta1.OwnedLabels{}
+		if err := v.decoder.DecodeRaw(req.OldObject, ownedLabelsOld); err != nil {
+			log.Error(err, "Failed to decode old OwnedLabels")
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if equality.Semantic.DeepEqual(ownedLabelsOld.Spec, ownedLabels.Spec) {
+			return admission.Allowed("spec unchanged")
+		}
+	}
+
+	if err := pkg.ValidateOwnedLabels(*ownedLabels); err != nil {
+		log.Info("Rejecting invalid OwnedLabels", "ownedLabels", ownedLabels.Name, "reason", err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("valid OwnedLabels")
+}
+
+// InjectDecoder injects the decoder.
+func (v *OwnedLabelsValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *OwnedLabelsValidator) SetupWebhookWithManager(mgr ctrl.Manager) {
+	hookServer := mgr.GetWebhookServer()
+	hookServer.Register("/validate-v1beta1-ownedlabels", &webhook.Admission{Handler: &OwnedLabelsValidator{}})
+}
+
+// +kubebuilder:webhook:path=/validate-v1beta1-labelmigration,mutating=false,failurePolicy=fail,sideEffects=None,groups=node-labels.openshift.io,resources=labelmigrations,verbs=create;update,versions=v1beta1,name=vlabelmigration.kb.io,admissionReviewVersions={v1,v1beta1}
+
+// LabelMigrationValidator validates LabelMigrations
+type LabelMigrationValidator struct {
+	decoder *admission.Decoder
+}
+
+func (v *LabelMigrationValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	migration := &v1beta1.LabelMigration{}
+	if err := v.decoder.Decode(req, migration); err != nil {
+		log.Error(err, "Failed to decode LabelMigration")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Update {
+		// don't block metadata updates of existing invalid LabelMigrations
+		migrationOld := &v1beta1.LabelMigration{}
+		if err := v.decoder.DecodeRaw(req.OldObject, migrationOld); err != nil {
+			log.Error(err, "Failed to decode old LabelMigration")
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if equality.Semantic.DeepEqual(migrationOld.Spec, migration.Spec) {
+			return admission.Allowed("spec unchanged")
+		}
+	}
+
+	if err := pkg.ValidateLabelMigration(*migration); err != nil {
+		log.Info("Rejecting invalid LabelMigration", "labelMigration", migration.Name, "reason", err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("valid LabelMigration")
+}
+
+// InjectDecoder injects the decoder.
+func (v *LabelMigrationValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *LabelMigrationValidator) SetupWebhookWithManager(mgr ctrl.Manager) {
+	hookServer := mgr.GetWebhookServer()
+	hookServer.Register("/validate-v1beta1-labelmigration", &webhook.Admission{Handler: &LabelMigrationValidator{}})
+}
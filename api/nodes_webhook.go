@@ -18,8 +18,11 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 
+	admissionv1 "k8s.io/api/admission/v1"
 	v1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,15 +34,29 @@ import (
 	"github.com/openshift-kni/node-label-operator/pkg"
 )
 
-// +kubebuilder:webhook:path=/label-v1-nodes,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=nodes,verbs=create,versions=v1,name=mnode.kb.io,admissionReviewVersions={v1,v1beta1}
+// +kubebuilder:webhook:path=/label-v1-nodes,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=nodes,verbs=create;update,versions=v1,name=mnode.kb.io,admissionReviewVersions={v1,v1beta1}
 
 // log is for logging in this package.
 var log = logf.Log.WithName("nodes-webhook")
 
-// NodeLabeler adds labels to Nodes
+// NodeLabeler adds labels, annotations and taints to new Nodes, and restores managed labels and annotations on
+// updated Nodes
 type NodeLabeler struct {
-	Client  client.Client
-	decoder *admission.Decoder
+	Client client.Client
+	// DryRun disables adding labels to new nodes
+	DryRun bool
+	// ForceOwnership overrides labels and annotations of new nodes, which are already set to another value
+	ForceOwnership bool
+	// OperatorUsername is the username of the operator's service account, its node updates are never modified.
+	// Node updates aren't handled at all without it.
+	OperatorUsername string
+	// DenyManagedEdits denies node updates by other users, which remove or modify managed labels or annotations,
+	// instead of restoring them
+	DenyManagedEdits bool
+	// ProtectLabels leaves node updates by users other than nodes to the NodeValidator, instead of restoring
+	// managed labels and annotations
+	ProtectLabels bool
+	decoder       *admission.Decoder
 }
 
 func (n *NodeLabeler) Handle(ctx context.Context, req admission.Request) admission.Response {
@@ -52,15 +69,42 @@ func (n *NodeLabeler) Handle(ctx context.Context, req admission.Request) admissi
 		log.Error(err, "Failed to decode node")
 		return admission.Errored(http.StatusBadRequest, err)
 	}
+	if pkg.IsIgnored(node) {
+		return admission.Allowed("node is ignored by annotation")
+	}
+
+	if n.DryRun {
+		return admission.Allowed("dry-run mode, no label added")
+	}
 
-	// get all label rules and apply labels as they match
+	// get all label rules and apply labels, annotations and taints as they match
 	allLabels := &v1beta1.LabelsList{}
 	if err = n.Client.List(context.TODO(), allLabels, &client.ListOptions{}); err != nil {
 		log.Error(err, "Failed to list Labels")
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	nodeModified := pkg.AddAllLabels(node, allLabels.Items, log)
+	liveLabels := pkg.LiveLabels(allLabels.Items)
+	if req.Operation == admissionv1.Update {
+		return n.handleUpdate(req, node, liveLabels)
+	}
+
+	desiredLabels := pkg.DesiredLabels(node, liveLabels, log)
+	desiredAnnotations := pkg.DesiredAnnotations(node, liveLabels, log)
+	if !n.ForceOwnership {
+		// labels and annotations of new nodes are set by the kubelet, don't override them
+		for _, name := range pkg.ForeignLabels(node, desiredLabels) {
+			log.Info("Not overriding label of new node", "node", node.Name, "labelName", name)
+			delete(desiredLabels, name)
+		}
+		for _, name := range pkg.ForeignAnnotations(node, desiredAnnotations) {
+			log.Info("Not overriding annotation of new node", "node", node.Name, "annotationName", name)
+			delete(desiredAnnotations, name)
+		}
+	}
+	nodeModified := pkg.ApplyLabels(node, desiredLabels, log)
+	nodeModified = pkg.ApplyAnnotations(node, desiredAnnotations, log) || nodeModified
+	nodeModified = pkg.AddAllTaints(node, liveLabels, log) || nodeModified
 
 	if nodeModified {
 		marshaledNode, err := json.Marshal(node)
@@ -74,6 +118,58 @@ func (n *NodeLabeler) Handle(ctx context.Context, req admission.Request) admissi
 	return admission.Allowed("no label added")
 }
 
+// handleUpdate restores the managed labels and annotations of the old node, which are removed or modified by the
+// given update, or denies the update if DenyManagedEdits is set.
+// Updates by the operator itself are allowed as they are, since it removes managed labels and annotations which
+// aren't covered anymore.
+// Without the username of the operator its own updates can't be identified, so nothing is restored.
+func (n *NodeLabeler) handleUpdate(req admission.Request, node *v1.Node, liveLabels []v1beta1.Labels) admission.Response {
+	if n.OperatorUsername == "" {
+		return admission.Allowed("operator can't be identified, managed labels aren't restored")
+	}
+	if req.UserInfo.Username == n.OperatorUsername {
+		return admission.Allowed("node updated by the operator")
+	}
+	if pkg.IsProtectionBypassed(node) {
+		return admission.Allowed("protection bypassed by annotation")
+	}
+	if n.ProtectLabels && !isNodeUser(req.UserInfo.Groups) {
+		return admission.Allowed("protected labels are validated")
+	}
+
+	oldNode := &v1.Node{}
+	if err := n.decoder.DecodeRaw(req.OldObject, oldNode); err != nil {
+		log.Error(err, "Failed to decode old node")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	restoredLabels := pkg.RestoreManagedLabels(oldNode, node, liveLabels, log)
+	restoredAnnotations := pkg.RestoreManagedAnnotations(oldNode, node, liveLabels, log)
+	if len(restoredLabels) == 0 && len(restoredAnnotations) == 0 {
+		return admission.Allowed("no managed label modified")
+	}
+
+	if n.DenyManagedEdits {
+		var keys []string
+		if len(restoredLabels) > 0 {
+			keys = append(keys, "labels "+strings.Join(restoredLabels, ", "))
+		}
+		if len(restoredAnnotations) > 0 {
+			keys = append(keys, "annotations "+strings.Join(restoredAnnotations, ", "))
+		}
+		log.Info("Denying modification of managed labels or annotations", "node", node.Name, "user", req.UserInfo.Username)
+		return admission.Denied(fmt.Sprintf("%s are managed by the node-label-operator and can't be modified",
+			strings.Join(keys, " and ")))
+	}
+
+	marshaledNode, err := json.Marshal(node)
+	if err != nil {
+		log.Error(err, "marshalling response went wrong")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledNode)
+}
+
 // InjectDecoder injects the decoder.
 func (n *NodeLabeler) InjectDecoder(d *admission.Decoder) error {
 	n.decoder = d
@@ -82,5 +178,111 @@ func (n *NodeLabeler) InjectDecoder(d *admission.Decoder) error {
 
 func (n *NodeLabeler) SetupWebhookWithManager(mgr ctrl.Manager) {
 	hookServer := mgr.GetWebhookServer()
-	hookServer.Register("/label-v1-nodes", &webhook.Admission{Handler: &NodeLabeler{Client: mgr.GetClient()}})
+	hookServer.Register("/label-v1-nodes", &webhook.Admission{Handler: &NodeLabeler{
+		Client:           mgr.GetClient(),
+		DryRun:           n.DryRun,
+		ForceOwnership:   n.ForceOwnership,
+		OperatorUsername: n.OperatorUsername,
+		DenyManagedEdits: n.DenyManagedEdits,
+		ProtectLabels:    n.ProtectLabels,
+	}})
+}
+
+// +kubebuilder:webhook:path=/validate-v1-nodes,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=nodes,verbs=update,versions=v1,name=vnode.kb.io,admissionReviewVersions={v1,v1beta1}
+
+// NodeValidator denies node updates which contradict the labels of a Labels CR, if enabled
+type NodeValidator struct {
+	Client client.Client
+	// ProtectLabels enables the validation
+	ProtectLabels bool
+	// OperatorUsername is the username of the operator's service account, its node updates are always allowed
+	OperatorUsername string
+	// AllowedGroups are the groups whose members are allowed to modify protected labels
+	AllowedGroups []string
+	decoder       *admission.Decoder
+}
+
+func (v *NodeValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	if !v.ProtectLabels {
+		return admission.Allowed("labels aren't protected")
+	}
+	if v.OperatorUsername != "" && req.UserInfo.Username == v.OperatorUsername {
+		return admission.Allowed("node updated by the operator")
+	}
+	for _, group := range req.UserInfo.Groups {
+		for _, allowedGroup := range v.AllowedGroups {
+			if group == allowedGroup {
+				return admission.Allowed("user is allowed to modify protected labels")
+			}
+		}
+	}
+
+	node := &v1.Node{}
+	if err := v.decoder.Decode(req, node); err != nil {
+		log.Error(err, "Failed to decode node")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if pkg.IsProtectionBypassed(node) {
+		log.Info("Allowing modification of protected labels", "node", node.Name, "user", req.UserInfo.Username,
+			"annotation", v1beta1.AnnotationAllowEdits)
+		return admission.Allowed("protection bypassed by annotation")
+	}
+	oldNode := &v1.Node{}
+	if err := v.decoder.DecodeRaw(req.OldObject, oldNode); err != nil {
+		log.Error(err, "Failed to decode old node")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	allLabels := &v1beta1.LabelsList{}
+	if err := v.Client.List(ctx, allLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list Labels")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	// the ignore annotation only stops the operator from managing the node, it doesn't bypass the protection,
+	// so ignored nodes are validated as if they weren't ignored
+	protectedNode := node
+	if pkg.IsIgnored(node) {
+		protectedNode = node.DeepCopy()
+		delete(protectedNode.Annotations, v1beta1.AnnotationIgnore)
+	}
+	violations := pkg.ProtectedLabelViolations(oldNode, protectedNode, pkg.LiveLabels(allLabels.Items), log)
+	if len(violations) == 0 {
+		return admission.Allowed("no protected label modified")
+	}
+	var messages []string
+	for _, violation := range violations {
+		messages = append(messages, fmt.Sprintf("label %s must be %q as defined by Labels %s",
+			violation.LabelName, violation.Value, violation.Labels))
+	}
+	log.Info("Denying modification of protected labels", "node", node.Name, "user", req.UserInfo.Username)
+	return admission.Denied(fmt.Sprintf("%s; set the %s=true annotation on the node for modifying protected labels anyway",
+		strings.Join(messages, ", "), v1beta1.AnnotationAllowEdits))
+}
+
+// InjectDecoder injects the decoder.
+func (v *NodeValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *NodeValidator) SetupWebhookWithManager(mgr ctrl.Manager) {
+	hookServer := mgr.GetWebhookServer()
+	hookServer.Register("/validate-v1-nodes", &webhook.Admission{Handler: &NodeValidator{
+		Client:           mgr.GetClient(),
+		ProtectLabels:    v.ProtectLabels,
+		OperatorUsername: v.OperatorUsername,
+		AllowedGroups:    v.AllowedGroups,
+	}})
+}
+
+// isNodeUser checks if the given groups contain the group of the kubelets
+func isNodeUser(groups []string) bool {
+	for _, group := range groups {
+		if group == "system:nodes" {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,112 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+	"github.com/openshift-kni/node-label-operator/pkg/test"
+)
+
+// Note: these tests call the node webhook directly with a given user, since the envtest API server doesn't
+// authenticate users. They aren't reusable by e2e tests.
+
+var _ = Describe("Node labeler", func() {
+
+	const nodeName = "dummy-operator-updates"
+
+	var labels *v1beta1.Labels
+	var labeler *NodeLabeler
+
+	BeforeEach(func() {
+		By("Creating a Labels CR")
+		labels = test.GetLabels(nodeName)
+		Expect(k8sClient.Create(context.Background(), labels)).Should(Succeed(), "labels should have been created")
+
+		scheme := runtime.NewScheme()
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+		decoder, err := admission.NewDecoder(scheme)
+		Expect(err).NotTo(HaveOccurred())
+		labeler = &NodeLabeler{
+			Client:           k8sClient,
+			OperatorUsername: operatorUsername,
+		}
+		Expect(labeler.InjectDecoder(decoder)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(context.Background(), labels)).Should(Succeed(), "labels should have been deleted")
+		Eventually(func() bool {
+			err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(labels), labels)
+			return err != nil && errors.IsNotFound(err)
+		}, test.Timeout, test.Interval).Should(BeTrue(), "labels should be away")
+	})
+
+	// removeManagedLabel returns an update request of the given user, which removes the managed label
+	removeManagedLabel := func(username string) admission.Request {
+		oldNode := test.GetNode(nodeName)
+		oldNode.Labels = map[string]string{test.LabelDomainName: test.LabelValue}
+		oldNode.Annotations = map[string]string{v1beta1.AnnotationManagedLabels: test.LabelDomainName}
+		node := oldNode.DeepCopy()
+		delete(node.Labels, test.LabelDomainName)
+
+		oldRaw, err := json.Marshal(oldNode)
+		Expect(err).NotTo(HaveOccurred())
+		raw, err := json.Marshal(node)
+		Expect(err).NotTo(HaveOccurred())
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Name:      nodeName,
+			Object:    runtime.RawExtension{Raw: raw},
+			OldObject: runtime.RawExtension{Raw: oldRaw},
+			UserInfo:  authenticationv1.UserInfo{Username: username},
+		}}
+	}
+
+	It("Should restore managed labels removed by other users", func() {
+		response := labeler.Handle(context.Background(), removeManagedLabel("other-user"))
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).NotTo(BeEmpty(), "label should have been restored")
+	})
+
+	It("Should not restore managed labels removed by the operator", func() {
+		response := labeler.Handle(context.Background(), removeManagedLabel(operatorUsername))
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(BeEmpty(), "label should not have been restored")
+	})
+
+	It("Should not handle node updates if the operator can't be identified", func() {
+		labeler.OperatorUsername = ""
+		response := labeler.Handle(context.Background(), removeManagedLabel(operatorUsername))
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(BeEmpty(), "label should not have been restored")
+	})
+
+})
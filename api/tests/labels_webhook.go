@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+	. "github.com/openshift-kni/node-label-operator/pkg/test"
+)
+
+// Note: this file hasn't the _test.go postfix because it is reused by e2e tests,
+// and _test.go files are only compiled if their own package is under test.
+
+var _ = Describe("Labels and OwnedLabels validation webhooks", func() {
+
+	var k8sClient client.Client
+
+	BeforeEach(func() {
+		k8sClient = *K8sClient // from test package
+	})
+
+	When("Creating a Labels CR", func() {
+
+		It("Should accept valid Labels", func() {
+			labels := GetLabels("valid-.*")
+			Expect(k8sClient.Create(context.Background(), labels)).Should(Succeed(), "labels should have been created")
+			Expect(k8sClient.Delete(context.Background(), labels)).Should(Succeed(), "labels should have been deleted")
+		})
+
+		It("Should accept valid templates", func() {
+			labels := GetLabels("valid-(?P<name>.*)")
+			labels.Spec.Labels = map[string]string{LabelDomainName: "${name}"}
+			Expect(k8sClient.Create(context.Background(), labels)).Should(Succeed(), "labels should have been created")
+			Expect(k8sClient.Delete(context.Background(), labels)).Should(Succeed(), "labels should have been deleted")
+		})
+
+		It("Should reject invalid node name patterns", func() {
+			labels := GetLabels("invalid-(.*")
+			err := k8sClient.Create(context.Background(), labels)
+			Expect(err).Should(HaveOccurred(), "labels should have been rejected")
+			Expect(errors.IsForbidden(err)).To(BeTrue(), "unexpected error")
+		})
+
+		It("Should reject invalid label names", func() {
+			labels := GetLabels("valid-.*")
+			labels.Spec.Labels = map[string]string{LabelDomain + "/in valid": LabelValue}
+			Expect(k8sClient.Create(context.Background(), labels)).ShouldNot(Succeed(), "labels should have been rejected")
+		})
+
+		It("Should reject invalid label values", func() {
+			labels := GetLabels("valid-.*")
+			labels.Spec.Labels = map[string]string{LabelDomainName: "in valid"}
+			Expect(k8sClient.Create(context.Background(), labels)).ShouldNot(Succeed(), "labels should have been rejected")
+		})
+
+		It("Should reject label names without domain", func() {
+			labels := GetLabels("valid-.*")
+			labels.Spec.Labels = map[string]string{LabelName: LabelValue}
+			Expect(k8sClient.Create(context.Background(), labels)).ShouldNot(Succeed(), "labels should have been rejected")
+		})
+
+		It("Should reject assignments with both maxNodes and percentage", func() {
+			labels := GetLabels("valid-.*")
+			maxNodes, percentage := int32(1), int32(10)
+			labels.Spec.Assignment = &v1beta1.NodeAssignment{MaxNodes: &maxNodes, Percentage: &percentage}
+			Expect(k8sClient.Create(context.Background(), labels)).ShouldNot(Succeed(), "labels should have been rejected")
+		})
+
+		It("Should reject match expressions which don't compile", func() {
+			labels := GetLabels("valid-.*")
+			labels.Spec.MatchExpression = "node.metadata.name.startsWith("
+			Expect(k8sClient.Create(context.Background(), labels)).ShouldNot(Succeed(), "labels should have been rejected")
+		})
+
+		It("Should accept match expressions referencing node labels", func() {
+			labels := GetLabels("valid-.*")
+			labels.Spec.MatchExpression = fmt.Sprintf("node.metadata.labels[%q] == %q", LabelDomainName, LabelValue)
+			Expect(k8sClient.Create(context.Background(), labels)).Should(Succeed(), "labels should have been accepted")
+			Expect(k8sClient.Delete(context.Background(), labels)).Should(Succeed(), "labels should have been deleted")
+		})
+
+		It("Should reject time windows ending before they start", func() {
+			labels := GetLabels("valid-.*")
+			notBefore := metav1.NewTime(time.Now().Add(time.Hour))
+			notAfter := metav1.NewTime(time.Now())
+			labels.Spec.NotBefore = &notBefore
+			labels.Spec.NotAfter = &notAfter
+			Expect(k8sClient.Create(context.Background(), labels)).ShouldNot(Succeed(), "labels should have been rejected")
+		})
+
+		It("Should reject node address selectors with invalid CIDRs", func() {
+			labels := GetLabels("valid-.*")
+			labels.Spec.NodeAddressSelector = &v1beta1.NodeAddressSelector{
+				Type:  v1.NodeInternalIP,
+				CIDRs: []string{"10.0.1.0/33"},
+			}
+			Expect(k8sClient.Create(context.Background(), labels)).ShouldNot(Succeed(), "labels should have been rejected")
+		})
+
+		It("Should reject node address labels which are also set in labels", func() {
+			labels := GetLabels("valid-.*")
+			labels.Spec.NodeAddressSelector = &v1beta1.NodeAddressSelector{
+				Type:      v1.NodeInternalIP,
+				CIDRs:     []string{"10.0.1.0/24"},
+				LabelName: LabelDomainName,
+				Values:    map[string]string{"10.0.1.0/24": LabelValueNew},
+			}
+			Expect(k8sClient.Create(context.Background(), labels)).ShouldNot(Succeed(), "labels should have been rejected")
+		})
+
+		It("Should reject inventories without node selection criteria", func() {
+			labels := GetLabels("")
+			labels.Spec.NodeNamePatterns = nil
+			labels.Spec.Inventory = &v1beta1.InventorySource{
+				ConfigMapName: "inventory",
+				Key:           "inventory.csv",
+				Format:        "csv",
+				KeyColumn:     "hostname",
+				LabelPrefix:   LabelDomain + "/",
+			}
+			Expect(k8sClient.Create(context.Background(), labels)).ShouldNot(Succeed(), "labels should have been rejected")
+		})
+
+		It("Should reject distributions with duplicate values", func() {
+			labels := GetLabels("valid-.*")
+			labels.Spec.Distributions = []v1beta1.LabelDistribution{{
+				Name:   LabelDomainNameNew,
+				Values: []string{LabelValue, LabelValue, LabelValueNew},
+			}}
+			Expect(k8sClient.Create(context.Background(), labels)).ShouldNot(Succeed(), "labels should have been rejected")
+		})
+
+	})
+
+	When("Creating an OwnedLabels CR", func() {
+
+		It("Should accept valid OwnedLabels", func() {
+			ownedLabels := GetOwnedLabels()
+			ownedLabels.Spec.NamePattern = pointer.StringPtr("foo.*")
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been created")
+			Expect(k8sClient.Delete(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been deleted")
+		})
+
+		It("Should reject invalid name patterns", func() {
+			ownedLabels := GetOwnedLabels()
+			ownedLabels.Spec.NamePattern = pointer.StringPtr("foo(.*")
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).ShouldNot(Succeed(), "ownedLabels should have been rejected")
+		})
+
+		It("Should reject OwnedLabels without domain and name pattern", func() {
+			ownedLabels := GetOwnedLabels()
+			ownedLabels.Spec = v1beta1.OwnedLabelsSpec{}
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).ShouldNot(Succeed(), "ownedLabels should have been rejected")
+		})
+
+		It("Should reject invalid removal limits", func() {
+			ownedLabels := GetOwnedLabels()
+			maxRemovals := intstr.FromString("many")
+			ownedLabels.Spec.MaxRemovals = &maxRemovals
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).ShouldNot(Succeed(), "ownedLabels should have been rejected")
+		})
+
+	})
+
+	When("Creating a LabelMigration CR", func() {
+
+		It("Should accept valid LabelMigrations", func() {
+			migration := GetLabelMigration(LabelDomain+"/foo.*", "example.openshift.io/foo")
+			migration.Spec.ValueMapping = map[string]string{LabelValue: LabelValueNew}
+			Expect(k8sClient.Create(context.Background(), migration)).Should(Succeed(), "labelMigration should have been created")
+			Expect(k8sClient.Delete(context.Background(), migration)).Should(Succeed(), "labelMigration should have been deleted")
+		})
+
+		It("Should reject targets matching the source pattern", func() {
+			migration := GetLabelMigration(LabelDomain+"/foo.*", LabelDomainName)
+			Expect(k8sClient.Create(context.Background(), migration)).ShouldNot(Succeed(), "labelMigration should have been rejected")
+		})
+
+	})
+
+})
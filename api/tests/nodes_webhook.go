@@ -97,6 +97,118 @@ var _ = Describe("Nodes webhook", func() {
 			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
 		})
 
+		It("Should restore managed labels when node is updated", func() {
+			By("Waiting for the label on the matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				_, ok := nodeMatching.Labels[LabelDomainName]
+				return ok
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Removing the label")
+			nodeOrig := nodeMatching.DeepCopy()
+			delete(nodeMatching.Labels, LabelDomainName)
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+			By("Verifying that label was restored by the update")
+			GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+			Expect(nodeMatching.Labels).To(HaveKeyWithValue(LabelDomainName, LabelValue), "label should have been restored")
+		})
+
+		It("Should deny modifications of managed labels with deny-managed-edits", func() {
+			if IsE2etest {
+				Skip("managed edits aren't denied in the e2e deployment")
+			}
+
+			By("Waiting for the label on the matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				_, ok := nodeMatching.Labels[LabelDomainName]
+				return ok
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Selecting the node for the webhook denying managed edits")
+			nodeOrig := nodeMatching.DeepCopy()
+			nodeMatching.Labels[DenyManagedEditsLabel] = "true"
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed(), "unmanaged label should be allowed")
+
+			By("Removing the managed label")
+			nodeOrig = nodeMatching.DeepCopy()
+			delete(nodeMatching.Labels, LabelDomainName)
+			err := k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))
+			Expect(err).To(MatchError(ContainSubstring(LabelDomainName)), "update should have been denied")
+
+			By("Verifying that the label is still set")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+			Expect(nodeMatching.Labels).To(HaveKeyWithValue(LabelDomainName, LabelValue), "label should not have been removed")
+		})
+
+		It("Should deny modifications of protected labels", func() {
+			if IsE2etest {
+				Skip("labels aren't protected in the e2e deployment")
+			}
+
+			By("Creating a Labels CR for the not matching node")
+			protectedLabels := GetLabels(nodeNotMatching.Name)
+			protectedLabels.Spec.Labels = LabelNewName
+			Expect(k8sClient.Create(context.Background(), protectedLabels)).Should(Succeed(), "labels should have been created")
+			defer func() {
+				Expect(k8sClient.Delete(context.Background(), protectedLabels)).Should(Succeed(), "labels should have been deleted")
+			}()
+
+			By("Setting the label to another value")
+			Eventually(func() error {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+				nodeOrig := nodeNotMatching.DeepCopy()
+				if nodeNotMatching.Labels == nil {
+					nodeNotMatching.Labels = map[string]string{}
+				}
+				// always modify the label, the Labels CR might not be known by the webhook yet
+				if nodeNotMatching.Labels[LabelDomainNameNew] == LabelValueNew {
+					nodeNotMatching.Labels[LabelDomainNameNew] = LabelValueNew + "-modified"
+				} else {
+					nodeNotMatching.Labels[LabelDomainNameNew] = LabelValueNew
+				}
+				return k8sClient.Patch(context.Background(), nodeNotMatching, client.MergeFrom(nodeOrig))
+			}, Timeout, Interval).Should(MatchError(ContainSubstring(protectedLabels.Name)), "update should have been denied")
+
+			By("Setting the label to another value with the allow-edits annotation")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+			nodeOrig := nodeNotMatching.DeepCopy()
+			if nodeNotMatching.Labels == nil {
+				nodeNotMatching.Labels = map[string]string{}
+			}
+			nodeNotMatching.Labels[LabelDomainNameNew] = LabelValueNew + "-allowed"
+			if nodeNotMatching.Annotations == nil {
+				nodeNotMatching.Annotations = map[string]string{}
+			}
+			nodeNotMatching.Annotations[v1beta1.AnnotationAllowEdits] = "true"
+			Expect(k8sClient.Patch(context.Background(), nodeNotMatching, client.MergeFrom(nodeOrig))).Should(Succeed(), "update should have been allowed")
+		})
+
+		It("Should deny modifications of protected labels on ignored nodes", func() {
+			if IsE2etest {
+				Skip("labels aren't protected in the e2e deployment")
+			}
+
+			By("Waiting for the label on the matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				_, ok := nodeMatching.Labels[LabelDomainName]
+				return ok
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Setting the label to another value together with the ignore annotation")
+			nodeOrig := nodeMatching.DeepCopy()
+			nodeMatching.Labels[LabelDomainName] = LabelValue + "-modified"
+			if nodeMatching.Annotations == nil {
+				nodeMatching.Annotations = map[string]string{}
+			}
+			nodeMatching.Annotations[v1beta1.AnnotationIgnore] = "true"
+			err := k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))
+			Expect(err).To(MatchError(ContainSubstring(labels.Name)), "update should have been denied")
+		})
+
 		It("Should not add labels when node not matches", func() {
 			By("Verifying that label was not set on not matching node")
 			Consistently(func() bool {
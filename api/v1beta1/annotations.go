@@ -0,0 +1,41 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// Node annotations used by the operator
+const (
+	// AnnotationManagedLabels records the comma separated names of the labels which were applied by the operator.
+	// Managed labels which aren't covered by any Labels anymore are removed from the node.
+	AnnotationManagedLabels = "node-labels.openshift.io/managed-labels"
+	// AnnotationManagedAnnotations records the comma separated names of the annotations which were applied by the
+	// operator. Managed annotations which aren't covered by any Labels anymore are removed from the node.
+	AnnotationManagedAnnotations = "node-labels.openshift.io/managed-annotations"
+	// AnnotationAllowEdits allows modifications of labels protected by Labels, when set to "true".
+	// It is meant for emergencies, and should be removed afterwards.
+	AnnotationAllowEdits = "node-labels.openshift.io/allow-edits"
+	// AnnotationIgnore excludes the node from all Labels and OwnedLabels, when set to "true". The node isn't
+	// modified by the operator at all, e.g. while it is under vendor maintenance.
+	AnnotationIgnore = "node-labels.openshift.io/ignore"
+)
+
+// Labels and OwnedLabels annotations
+const (
+	// AnnotationApproveRemovals approves removals of owned or managed labels which exceed the removal limit, when set
+	// to "true".
+	// It is removed by the operator after the removals are done.
+	AnnotationApproveRemovals = "node-labels.openshift.io/approve-removals"
+)
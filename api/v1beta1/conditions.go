@@ -0,0 +1,84 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// Condition types of Labels, OwnedLabels and LabelMigrations
+const (
+	// ConditionReady is true when all matching nodes are in the desired state
+	ConditionReady = "Ready"
+	// ConditionInvalidPattern is true when the CR is invalid, e.g. because of invalid patterns or selectors
+	ConditionInvalidPattern = "InvalidPattern"
+	// ConditionConflicting is true when another Labels with higher precedence sets a label to another value on the same node
+	ConditionConflicting = "Conflicting"
+	// ConditionRemovalBlocked is true when more nodes would lose owned or managed labels at once than allowed
+	ConditionRemovalBlocked = "RemovalBlocked"
+	// ConditionInventoryFailed is true when the inventory of a Labels can't be read
+	ConditionInventoryFailed = "InventoryFailed"
+	// ConditionExpressionFailed is true when the match expression can't be evaluated for some nodes
+	ConditionExpressionFailed = "ExpressionFailed"
+	// ConditionDependencyCycle is true when a Labels selects nodes by labels, which are set by Labels depending on it
+	ConditionDependencyCycle = "DependencyCycle"
+	// ConditionActive is true when the current time is within the time window of a Labels set by notBefore,
+	// notAfter and ttl
+	ConditionActive = "Active"
+)
+
+// Condition reasons of Labels, OwnedLabels and LabelMigrations
+const (
+	// ReasonApplied is used when all matching nodes are in the desired state
+	ReasonApplied = "Applied"
+	// ReasonProgressing is used when not all matching nodes are in the desired state yet
+	ReasonProgressing = "Progressing"
+	// ReasonInvalidPattern is used when the CR is invalid
+	ReasonInvalidPattern = "InvalidPattern"
+	// ReasonValid is used when the CR is valid
+	ReasonValid = "Valid"
+	// ReasonConflicting is used when another Labels with higher precedence sets a label to another value on the same node
+	ReasonConflicting = "Conflicting"
+	// ReasonNoConflicts is used when no other Labels with higher precedence sets a label to another value on the same node
+	ReasonNoConflicts = "NoConflicts"
+	// ReasonDryRun is used when the CR or the operator is in dry-run mode, and changes are only previewed
+	ReasonDryRun = "DryRun"
+	// ReasonRemovalLimitExceeded is used when more nodes would lose owned or managed labels at once than allowed
+	ReasonRemovalLimitExceeded = "RemovalLimitExceeded"
+	// ReasonRemovalAllowed is used when owned or managed labels can be removed, because the removal limit isn't
+	// exceeded or the removals were approved
+	ReasonRemovalAllowed = "RemovalAllowed"
+	// ReasonInventoryFailed is used when the inventory of a Labels can't be read
+	ReasonInventoryFailed = "InventoryFailed"
+	// ReasonInventoryRead is used when the inventory of a Labels was read successfully
+	ReasonInventoryRead = "InventoryRead"
+	// ReasonExpressionFailed is used when the match expression can't be evaluated for some nodes
+	ReasonExpressionFailed = "ExpressionFailed"
+	// ReasonExpressionEvaluated is used when the match expression was evaluated for all nodes
+	ReasonExpressionEvaluated = "ExpressionEvaluated"
+	// ReasonDependencyCycle is used when a Labels selects nodes by labels, which are set by Labels depending on it
+	ReasonDependencyCycle = "DependencyCycle"
+	// ReasonNoDependencyCycle is used when a Labels doesn't depend on itself
+	ReasonNoDependencyCycle = "NoDependencyCycle"
+	// ReasonMigrationConflict is used when the target label of a LabelMigration is already set to another value
+	ReasonMigrationConflict = "MigrationConflict"
+	// ReasonScheduled is used when the time window of a Labels hasn't started yet
+	ReasonScheduled = "Scheduled"
+	// ReasonExpired is used when the time window of a Labels is over
+	ReasonExpired = "Expired"
+	// ReasonActive is used when the current time is within the time window of a Labels
+	ReasonActive = "Active"
+)
+
+// MaxStatusNodes is the maximum number of node names listed in the status
+const MaxStatusNodes = 10
@@ -0,0 +1,161 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Important: Run "make" to regenerate code after modifying this file
+
+// LabelMigrationSpec defines the desired state of LabelMigration
+type LabelMigrationSpec struct {
+	// SourcePattern defines the name pattern of the labels which are migrated, in domain/name format
+	// String start and end anchors (^/$) will be added automatically
+	SourcePattern string `json:"sourcePattern"`
+
+	// Target is the name of the label the values are copied to, in domain/name format
+	Target string `json:"target"`
+
+	// ValueMapping maps values of the source labels to values of the target label.
+	// Values without mapping are copied unchanged.
+	// +optional
+	ValueMapping map[string]string `json:"valueMapping,omitempty"`
+
+	// NodeSelector limits the migration to the nodes matching this selector. Defaults to all nodes.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+}
+
+// LabelMigrationPhase is the phase of a LabelMigration
+type LabelMigrationPhase string
+
+// LabelMigration phases
+const (
+	// MigrationPhaseCopying is used until every matching node has the target label
+	MigrationPhaseCopying LabelMigrationPhase = "Copying"
+	// MigrationPhaseRemoving is used while owned source labels are removed
+	MigrationPhaseRemoving LabelMigrationPhase = "Removing"
+	// MigrationPhaseCompleted is used when the target label is set and no owned source label is left on any matching node
+	MigrationPhaseCompleted LabelMigrationPhase = "Completed"
+)
+
+// NodeMigrationState is the migration state of a single node
+type NodeMigrationState string
+
+// Node migration states
+const (
+	// NodeMigrationPending is used when the target label isn't set yet
+	NodeMigrationPending NodeMigrationState = "Pending"
+	// NodeMigrationConflict is used when the target label is already set to another value, or the source labels
+	// of the node map to different values
+	NodeMigrationConflict NodeMigrationState = "Conflict"
+	// NodeMigrationCopied is used when the target label is set, and the source labels are waiting for removal
+	NodeMigrationCopied NodeMigrationState = "Copied"
+	// NodeMigrationRemoved is used when the source labels were removed
+	NodeMigrationRemoved NodeMigrationState = "Removed"
+	// NodeMigrationNotOwned is used when the target label is set, but a source label isn't owned by any OwnedLabels
+	// and so is kept
+	NodeMigrationNotOwned NodeMigrationState = "NotOwned"
+	// NodeMigrationCovered is used when the target label is set, but a source label is still covered by a Labels
+	// and so is kept
+	NodeMigrationCovered NodeMigrationState = "Covered"
+)
+
+// LabelMigrationStatus defines the observed state of LabelMigration
+type LabelMigrationStatus struct {
+	// ObservedGeneration is the generation of the LabelMigration which was used for updating this status
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is the current phase of the migration, one of Copying, Removing and Completed
+	// +optional
+	Phase LabelMigrationPhase `json:"phase,omitempty"`
+
+	// MatchedNodesCount is the number of nodes which are migrated
+	MatchedNodesCount int32 `json:"matchedNodesCount"`
+
+	// CopiedNodesCount is the number of migrated nodes which have the target label
+	CopiedNodesCount int32 `json:"copiedNodesCount"`
+
+	// RemovedNodesCount is the number of migrated nodes whose source labels were removed, including matching nodes
+	// which have the target label without source labels
+	RemovedNodesCount int32 `json:"removedNodesCount"`
+
+	// Nodes records the migration state of the migrated nodes in alphabetical order, limited to 10 nodes.
+	// Nodes with conflicts or pending copies are listed first.
+	// +optional
+	Nodes []NodeMigration `json:"nodes,omitempty"`
+
+	// Conditions represent the latest available observations of the LabelMigration's state.
+	// Known condition types are Ready and InvalidPattern.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// NodeMigration describes the migration state of a node
+type NodeMigration struct {
+	// NodeName is the name of the node
+	NodeName string `json:"nodeName"`
+
+	// SourceLabels are the names of the source labels of the node
+	SourceLabels []string `json:"sourceLabels"`
+
+	// Value is the value of the target label
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// State is the migration state of the node
+	State NodeMigrationState `json:"state"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Matched",type=integer,JSONPath=`.status.matchedNodesCount`,description="Number of migrated nodes"
+// +kubebuilder:printcolumn:name="Copied",type=integer,JSONPath=`.status.copiedNodesCount`,description="Number of nodes with the target label"
+// +kubebuilder:printcolumn:name="Removed",type=integer,JSONPath=`.status.removedNodesCount`,description="Number of nodes without source labels"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// LabelMigration is the Schema for the labelmigrations API. LabelMigrations rename node labels: the values of the
+// source labels are copied to the target label, and once every matching node has the target label, the owned
+// source labels are removed.
+type LabelMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LabelMigrationSpec   `json:"spec,omitempty"`
+	Status LabelMigrationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LabelMigrationList contains a list of LabelMigration
+type LabelMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LabelMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LabelMigration{}, &LabelMigrationList{})
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,19 +28,399 @@ import (
 type LabelsSpec struct {
 	// NodeNamePatterns defines a list of node name regex patterns for which the given labels should be set.
 	// String start and end anchors (^/$) will be added automatically
-	NodeNamePatterns []string `json:"nodeNamePatterns"`
+	// +optional
+	NodeNamePatterns []string `json:"nodeNamePatterns,omitempty"`
 
-	// Label defines the labels which should be set if one of the node name patterns matches
+	// NodeSelector selects nodes by their labels
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// NodeFieldSelectorTerms selects nodes by their fields. The terms are ORed.
+	// +optional
+	NodeFieldSelectorTerms []NodeFieldSelectorTerm `json:"nodeFieldSelectorTerms,omitempty"`
+
+	// NodeAddressSelector selects nodes by their addresses
+	// +optional
+	NodeAddressSelector *NodeAddressSelector `json:"nodeAddressSelector,omitempty"`
+
+	// NodeResourceRequirements selects nodes by their capacity or allocatable resources. The requirements are ANDed.
+	// +optional
+	NodeResourceRequirements []NodeResourceRequirement `json:"nodeResourceRequirements,omitempty"`
+
+	// NodeConditionRequirements selects nodes by the status of their conditions. The requirements are ANDed.
+	// +optional
+	NodeConditionRequirements []NodeConditionRequirement `json:"nodeConditionRequirements,omitempty"`
+
+	// MatchExpression is a CEL expression, which selects nodes if it evaluates to true. The node is available
+	// as the node variable, e.g. node.status.nodeInfo.architecture == "arm64" && node.metadata.name.startsWith("edge-")
+	// Node fields with zero values are missing, and need to be checked with has(), e.g. !has(node.spec.unschedulable)
+	// Node labels read by the expression order Labels like labels of the node selector do.
+	// +optional
+	MatchExpression string `json:"matchExpression,omitempty"`
+
+	// ExcludeNodeNamePatterns defines a list of node name regex patterns of nodes which never match, even if they
+	// match all other node selection criteria.
+	// String start and end anchors (^/$) will be added automatically
+	// +optional
+	ExcludeNodeNamePatterns []string `json:"excludeNodeNamePatterns,omitempty"`
+
+	// ExcludeNodeSelector selects nodes by their labels which never match, even if they match all other node
+	// selection criteria.
+	// +optional
+	ExcludeNodeSelector *metav1.LabelSelector `json:"excludeNodeSelector,omitempty"`
+
+	// Assignment limits the nodes which get the labels to a subset of the nodes matching the other node selection
+	// criteria. Assignments of different Labels can overlap, and new nodes aren't labeled before they are assigned.
+	// +optional
+	Assignment *NodeAssignment `json:"assignment,omitempty"`
+
+	// Priority defines the precedence of this Labels in case multiple Labels set the same label to different values
+	// on the same node. The Labels with the highest priority wins. On equal priority the Labels created first wins,
+	// and if they were created at the same time, the Labels with the alphabetically first namespace/name wins.
+	// Defaults to 0.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// DryRun disables adding and modifying labels of this Labels on nodes. Instead the changes which would be applied
+	// are reported in the status and as events.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// NotBefore is the time from which on the labels, annotations and taints are applied. Defaults to immediately.
+	// +optional
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+
+	// NotAfter is the time from which on the labels, annotations and taints are removed again. Defaults to never.
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// TTL is the duration after which the labels, annotations and taints are removed again, e.g. 48h. It starts at
+	// notBefore if set, otherwise at the creation of the Labels. If notAfter is set as well, the earlier time wins.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// Label defines the labels which should be set if the node matches.
+	// A node matches if it matches all of the given node selection criteria:
+	// - one of the node name patterns, if given AND
+	// - the node selector, if given AND
+	// - one of the node field selector terms, if given AND
+	// - the node address selector, if given AND
+	// - all node resource requirements, if given AND
+	// - all node condition requirements, if given AND
+	// - the match expression, if given AND
+	// - the assignment, if given
+	// If no node selection criteria is given, no node matches.
+	// Nodes matching the exclude node name patterns or the exclude node selector, and nodes with the
+	// node-labels.openshift.io/ignore=true annotation never match.
 	// Format of label must be domain/name=value
-	Labels map[string]string `json:"labels"`
+	// Label names and values can be templates, which reference capture groups of the matching node name pattern,
+	// e.g. ${1} or ${rack} for the pattern worker-r(?P<rack>[0-9]+)-s[0-9]+
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Distributions defines labels whose values are spread evenly over the matching nodes
+	// +optional
+	Distributions []LabelDistribution `json:"distributions,omitempty"`
+
+	// Inventory defines labels which are read from a host inventory table in a ConfigMap. Its rows are only matched to
+	// the nodes matching the node selection criteria, which need to be set as well.
+	// +optional
+	Inventory *InventorySource `json:"inventory,omitempty"`
+
+	// Annotations defines the annotations which should be set if the node matches, using the same node selection
+	// criteria and templates as labels.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Taints defines the taints which should be set if the node matches, using the same node selection criteria
+	// as labels. A taint is identified by its key and effect.
+	// +optional
+	Taints []v1.Taint `json:"taints,omitempty"`
+}
+
+// NodeFieldSelectorTerm defines a list of node field requirements. The requirements are ANDed.
+type NodeFieldSelectorTerm struct {
+	// MatchFields is a list of node field requirements.
+	// Supported keys are status.nodeInfo.architecture, status.nodeInfo.operatingSystem,
+	// status.nodeInfo.kernelVersion, status.nodeInfo.osImage, status.nodeInfo.kubeletVersion,
+	// status.nodeInfo.containerRuntimeVersion and spec.providerID.
+	// Supported operators are In, NotIn, Exists and DoesNotExist.
+	MatchFields []v1.NodeSelectorRequirement `json:"matchFields"`
+}
+
+// NodeAddressSelector selects nodes which have an address of the given type in one of the given CIDRs
+type NodeAddressSelector struct {
+	// Type is the type of the node addresses, InternalIP or ExternalIP
+	// +kubebuilder:validation:Enum=InternalIP;ExternalIP
+	Type v1.NodeAddressType `json:"type"`
+
+	// CIDRs is a list of IPv4 or IPv6 CIDRs, e.g. 10.0.1.0/24 or fd00:1::/64
+	// +kubebuilder:validation:MinItems=1
+	CIDRs []string `json:"cidrs"`
+
+	// LabelName is the name of a label, in domain/name format, which is set to the value of the first CIDR
+	// containing a node address
+	// +optional
+	LabelName string `json:"labelName,omitempty"`
+
+	// Values maps the CIDRs to the values of the LabelName label
+	// +optional
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// NodeResourceRequirement compares a resource quantity of a node with a value
+type NodeResourceRequirement struct {
+	// Source is the node status field which contains the resource quantity, Capacity or Allocatable.
+	// Defaults to Allocatable.
+	// +kubebuilder:validation:Enum=Capacity;Allocatable
+	// +optional
+	Source string `json:"source,omitempty"`
+
+	// Name is the name of the resource, e.g. memory, nvidia.com/gpu or hugepages-1Gi.
+	// Resources which a node doesn't report have a quantity of 0.
+	Name v1.ResourceName `json:"name"`
+
+	// Operator is the comparison operator, one of Gt, Ge, Lt, Le and Eq
+	// +kubebuilder:validation:Enum=Gt;Ge;Lt;Le;Eq
+	Operator ResourceOperator `json:"operator"`
+
+	// Value is the quantity the resource quantity of the node is compared with
+	Value resource.Quantity `json:"value"`
+}
+
+// ResourceOperator is the comparison operator of a NodeResourceRequirement
+type ResourceOperator string
+
+// Resource comparison operators
+const (
+	ResourceOpGt ResourceOperator = "Gt"
+	ResourceOpGe ResourceOperator = "Ge"
+	ResourceOpLt ResourceOperator = "Lt"
+	ResourceOpLe ResourceOperator = "Le"
+	ResourceOpEq ResourceOperator = "Eq"
+)
+
+// Node resource sources
+const (
+	ResourceSourceCapacity    = "Capacity"
+	ResourceSourceAllocatable = "Allocatable"
+)
+
+// NodeConditionRequirement selects nodes by the status of one of their conditions
+type NodeConditionRequirement struct {
+	// Type is the type of the node condition, e.g. Ready or DiskPressure
+	Type v1.NodeConditionType `json:"type"`
+
+	// Status is the required status of the condition
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status v1.ConditionStatus `json:"status"`
+
+	// MinDuration is the minimum duration since the last transition of the condition, e.g. 10m
+	// +optional
+	MinDuration *metav1.Duration `json:"minDuration,omitempty"`
+}
+
+// NodeAssignment selects a stable subset of the matching nodes. Nodes are selected by rendezvous hashing of their UID,
+// and stay assigned as long as they match and the number of assigned nodes doesn't exceed the limit. Nodes which don't
+// match anymore are replaced by other matching nodes.
+// Exactly one of MaxNodes and Percentage must be set.
+// The hash is seeded with the namespace and name of the Labels, so assignments of different Labels are independent
+// and can overlap. Use a LabelDistribution for splitting nodes into disjoint groups.
+// New nodes are assigned by the reconciler, after they were created, so they aren't labeled by the admission webhook.
+type NodeAssignment struct {
+	// MaxNodes is the number of matching nodes which are assigned
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxNodes *int32 `json:"maxNodes,omitempty"`
+
+	// Percentage is the percentage of matching nodes which are assigned, rounded up
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percentage *int32 `json:"percentage,omitempty"`
+}
+
+// LabelDistribution spreads the given values of a label evenly over the matching nodes. The number of nodes per value
+// differs by one at most. Nodes keep their value as long as the distribution stays balanced, so joining and leaving
+// nodes only move the minimum number of nodes to another value.
+type LabelDistribution struct {
+	// Name is the name of the label, in domain/name format
+	Name string `json:"name"`
+
+	// Values are the candidate values of the label, without duplicates
+	// +kubebuilder:validation:MinItems=1
+	Values []string `json:"values"`
 }
 
+// InventorySource defines a host inventory table in a ConfigMap. Each row describes a node, and its columns are turned
+// into labels of the matching node.
+type InventorySource struct {
+	// ConfigMapName is the name of the ConfigMap in the namespace of the Labels
+	ConfigMapName string `json:"configMapName"`
+
+	// Key is the key of the ConfigMap data which contains the inventory table
+	Key string `json:"key"`
+
+	// Format is the format of the inventory table. A csv table starts with a header row containing the column names.
+	// A yaml table is a list of objects with string values, their keys are the column names.
+	// +kubebuilder:validation:Enum=csv;yaml
+	Format string `json:"format"`
+
+	// KeyColumn is the column which identifies the node of a row
+	KeyColumn string `json:"keyColumn"`
+
+	// MatchBy defines the node property which is compared with the key column:
+	// - Hostname: the node name or its kubernetes.io/hostname label
+	// - ProviderID: the provider ID of the node
+	// - Address: any of the node addresses, e.g. its InternalIP
+	// Defaults to Hostname. MAC addresses aren't part of the Node object, so matching by MAC isn't supported.
+	// +kubebuilder:validation:Enum=Hostname;ProviderID;Address
+	// +optional
+	MatchBy string `json:"matchBy,omitempty"`
+
+	// LabelPrefix is prepended to the column names for building the label names, in domain/ format,
+	// e.g. inventory.example.com/
+	LabelPrefix string `json:"labelPrefix"`
+
+	// Columns limits the columns which are turned into labels. Defaults to all columns except the key column.
+	// +optional
+	Columns []string `json:"columns,omitempty"`
+}
+
+// Inventory node matching modes
+const (
+	InventoryMatchByHostname   = "Hostname"
+	InventoryMatchByProviderID = "ProviderID"
+	InventoryMatchByAddress    = "Address"
+)
+
 // LabelsStatus defines the observed state of Labels
 type LabelsStatus struct {
+	// ObservedGeneration is the generation of the Labels which was used for updating this status
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// MatchedNodesCount is the number of nodes matching the node selection criteria
+	MatchedNodesCount int32 `json:"matchedNodesCount"`
+
+	// MatchedNodes lists the names of the matching nodes, limited to the first 10 names in alphabetical order
+	// +optional
+	MatchedNodes []string `json:"matchedNodes,omitempty"`
+
+	// AssignedNodes lists the names of all assigned nodes in alphabetical order, if an assignment is configured.
+	// Assigned nodes are kept from this list, so it is only limited by maxNodes or percentage of the assignment.
+	// +optional
+	AssignedNodes []string `json:"assignedNodes,omitempty"`
+
+	// Distributions records the values of the distributed labels per node. Nodes keep their values from this list,
+	// so it isn't limited, and grows with the number of matching nodes and distributions.
+	// +optional
+	Distributions []LabelDistributionStatus `json:"distributions,omitempty"`
+
+	// Inventory records the labels of the matching nodes with an inventory row, which were read from the inventory.
+	// The nodes are labeled from this list, so it isn't limited, and grows with the number of matching nodes.
+	// +optional
+	Inventory []NodeInventory `json:"inventory,omitempty"`
+
+	// Preview lists the label changes which would be applied in case the Labels wasn't in dry-run mode,
+	// limited to the first 10 nodes in alphabetical order
+	// +optional
+	Preview []NodeLabelsPreview `json:"preview,omitempty"`
+
+	// AppliedSpec is the spec whose labels and annotations were applied to nodes last. It is updated once the labels
+	// and annotations which aren't covered by the current spec anymore are removed from all nodes. Labels in
+	// dry-run mode keep covering the labels and annotations of this spec, so they aren't removed from nodes.
+	// +optional
+	AppliedSpec *LabelsSpec `json:"appliedSpec,omitempty"`
+
+	// RemovableNodes lists the names of all nodes which can lose managed labels or annotations of the applied spec
+	// within the removal limit, in alphabetical order. It is only set if a removal limit applies and isn't exceeded.
+	// Managed labels and annotations of the applied spec aren't removed from other nodes, until they are listed here
+	// or the removals are approved.
+	// +optional
+	RemovableNodes []string `json:"removableNodes,omitempty"`
+
+	// NextTransitionTime is the time at which the Labels is activated or expires next, if notBefore, notAfter or
+	// ttl are set
+	// +optional
+	NextTransitionTime *metav1.Time `json:"nextTransitionTime,omitempty"`
+
+	// Conditions represent the latest available observations of the Labels' state.
+	// Known condition types are Ready, InvalidPattern, Conflicting, InventoryFailed, ExpressionFailed,
+	// DependencyCycle, Active and RemovalBlocked.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// LabelDistributionStatus describes the current distribution of the values of a label
+type LabelDistributionStatus struct {
+	// Name is the name of the label
+	Name string `json:"name"`
+
+	// Nodes maps the names of all matching nodes to their value, it isn't limited
+	// +optional
+	Nodes map[string]string `json:"nodes,omitempty"`
+
+	// ValueCounts is the number of nodes per value
+	// +optional
+	ValueCounts map[string]int32 `json:"valueCounts,omitempty"`
+}
+
+// NodeInventory describes the inventory labels of a node
+type NodeInventory struct {
+	// NodeName is the name of the node
+	NodeName string `json:"nodeName"`
+
+	// Labels are the labels read from the inventory row of the node
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// NodeLabelsPreview describes the label changes on a node, which would be applied if dry-run mode was disabled
+type NodeLabelsPreview struct {
+	// NodeName is the name of the node
+	NodeName string `json:"nodeName"`
+
+	// WouldAdd lists the labels which would be added
+	// +optional
+	WouldAdd map[string]string `json:"wouldAdd,omitempty"`
+
+	// WouldChange lists the labels which would be set to another value
+	// +optional
+	WouldChange map[string]string `json:"wouldChange,omitempty"`
+
+	// WouldRemove lists the names of the labels which would be removed
+	// +optional
+	WouldRemove []string `json:"wouldRemove,omitempty"`
+
+	// WouldAddAnnotations lists the annotations which would be added or set to another value
+	// +optional
+	WouldAddAnnotations map[string]string `json:"wouldAddAnnotations,omitempty"`
+
+	// WouldRemoveAnnotations lists the names of the annotations which would be removed
+	// +optional
+	WouldRemoveAnnotations []string `json:"wouldRemoveAnnotations,omitempty"`
+
+	// WouldAddTaints lists the taints which would be added or set to another value
+	// +optional
+	WouldAddTaints []v1.Taint `json:"wouldAddTaints,omitempty"`
+
+	// WouldRemoveTaints lists the taints which would be removed
+	// +optional
+	WouldRemoveTaints []v1.Taint `json:"wouldRemoveTaints,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Matched",type=integer,JSONPath=`.status.matchedNodesCount`,description="Number of matching nodes"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Labels is the Schema for the labels API. Labels define which labels should be added to which nodes.
 type Labels struct {
@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Important: Run "make" to regenerate code after modifying this file
@@ -40,14 +41,90 @@ type OwnedLabelsSpec struct {
 	// then the label will be removed
 	// String start and end anchors (^/$) will be added automatically
 	NamePattern *string `json:"namePattern,omitempty"`
+
+	// AnnotationKeyPattern defines the annotation key pattern which is owned by this operator
+	// If a node annotation
+	// - matches this key pattern AND
+	// - no label rule with this annotation matches
+	// then the annotation will be removed
+	// String start and end anchors (^/$) will be added automatically
+	// +optional
+	AnnotationKeyPattern *string `json:"annotationKeyPattern,omitempty"`
+
+	// TaintKeyPattern defines the taint key pattern which is owned by this operator
+	// If a node taint
+	// - matches this key pattern AND
+	// - no label rule with a taint with the same key and effect matches
+	// then the taint will be removed
+	// String start and end anchors (^/$) will be added automatically
+	// +optional
+	TaintKeyPattern *string `json:"taintKeyPattern,omitempty"`
+
+	// MatchExpression is a CEL expression, which limits the nodes on which labels, annotations and taints are owned
+	// to the nodes for which it evaluates to true. The node is available as the node variable. Defaults to all nodes.
+	// +optional
+	MatchExpression string `json:"matchExpression,omitempty"`
+
+	// DryRun disables removing owned labels from nodes. Instead the changes which would be applied
+	// are reported in the status and as events.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// MaxRemovals limits the number of nodes which can lose owned labels at once. It can be an absolute number
+	// or a percentage of all nodes, e.g. "10%". If more nodes would lose owned labels, no owned label is removed,
+	// and the RemovalBlocked condition is set, until the node-labels.openshift.io/approve-removals annotation
+	// is set to "true". Unlimited by default.
+	// +optional
+	MaxRemovals *intstr.IntOrString `json:"maxRemovals,omitempty"`
 }
 
 // OwnedLabelsStatus defines the observed state of OwnedLabels
 type OwnedLabelsStatus struct {
+	// ObservedGeneration is the generation of the OwnedLabels which was used for updating this status
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// MatchedNodesCount is the number of nodes having labels which are owned by this OwnedLabels
+	MatchedNodesCount int32 `json:"matchedNodesCount"`
+
+	// MatchedNodes lists the names of the nodes having owned labels, limited to the first 10 names in alphabetical order
+	// +optional
+	MatchedNodes []string `json:"matchedNodes,omitempty"`
+
+	// RemovedLabelsCount is the number of uncovered owned labels which were removed from nodes in the last removal pass
+	RemovedLabelsCount int32 `json:"removedLabelsCount"`
+
+	// LastRemovalTime is the time of the last removal pass
+	// +optional
+	LastRemovalTime *metav1.Time `json:"lastRemovalTime,omitempty"`
+
+	// Preview lists the label removals which would be applied in case the OwnedLabels wasn't in dry-run mode,
+	// limited to the first 10 nodes in alphabetical order
+	// +optional
+	Preview []NodeLabelsPreview `json:"preview,omitempty"`
+
+	// RemovableNodes lists the names of all nodes which can lose owned labels within the removal limits, in
+	// alphabetical order. It is only set if a removal limit applies and isn't exceeded. Owned labels aren't removed
+	// from other nodes, until they are listed here or the removals are approved.
+	// +optional
+	RemovableNodes []string `json:"removableNodes,omitempty"`
+
+	// Conditions represent the latest available observations of the OwnedLabels' state.
+	// Known condition types are Ready, InvalidPattern, RemovalBlocked and ExpressionFailed.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Matched",type=integer,JSONPath=`.status.matchedNodesCount`,description="Number of nodes with owned labels"
+// +kubebuilder:printcolumn:name="Removed",type=integer,JSONPath=`.status.removedLabelsCount`,description="Number of labels removed in the last removal pass"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // OwnedLabels is the Schema for the ownedlabels API. They define which node labels are owned by this operator
 // and can safely be removed in case no label rule matches anymore.
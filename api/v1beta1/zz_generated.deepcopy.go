@@ -21,16 +21,203 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InventorySource) DeepCopyInto(out *InventorySource) {
+	*out = *in
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InventorySource.
+func (in *InventorySource) DeepCopy() *InventorySource {
+	if in == nil {
+		return nil
+	}
+	out := new(InventorySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelDistribution) DeepCopyInto(out *LabelDistribution) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelDistribution.
+func (in *LabelDistribution) DeepCopy() *LabelDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelDistributionStatus) DeepCopyInto(out *LabelDistributionStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ValueCounts != nil {
+		in, out := &in.ValueCounts, &out.ValueCounts
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelDistributionStatus.
+func (in *LabelDistributionStatus) DeepCopy() *LabelDistributionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelDistributionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelMigration) DeepCopyInto(out *LabelMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelMigration.
+func (in *LabelMigration) DeepCopy() *LabelMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LabelMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelMigrationList) DeepCopyInto(out *LabelMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LabelMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelMigrationList.
+func (in *LabelMigrationList) DeepCopy() *LabelMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LabelMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelMigrationSpec) DeepCopyInto(out *LabelMigrationSpec) {
+	*out = *in
+	if in.ValueMapping != nil {
+		in, out := &in.ValueMapping, &out.ValueMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelMigrationSpec.
+func (in *LabelMigrationSpec) DeepCopy() *LabelMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelMigrationStatus) DeepCopyInto(out *LabelMigrationStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelMigrationStatus.
+func (in *LabelMigrationStatus) DeepCopy() *LabelMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Labels) DeepCopyInto(out *Labels) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Labels.
@@ -91,6 +278,65 @@ func (in *LabelsSpec) DeepCopyInto(out *LabelsSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeFieldSelectorTerms != nil {
+		in, out := &in.NodeFieldSelectorTerms, &out.NodeFieldSelectorTerms
+		*out = make([]NodeFieldSelectorTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeAddressSelector != nil {
+		in, out := &in.NodeAddressSelector, &out.NodeAddressSelector
+		*out = new(NodeAddressSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeResourceRequirements != nil {
+		in, out := &in.NodeResourceRequirements, &out.NodeResourceRequirements
+		*out = make([]NodeResourceRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeConditionRequirements != nil {
+		in, out := &in.NodeConditionRequirements, &out.NodeConditionRequirements
+		*out = make([]NodeConditionRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExcludeNodeNamePatterns != nil {
+		in, out := &in.ExcludeNodeNamePatterns, &out.ExcludeNodeNamePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeNodeSelector != nil {
+		in, out := &in.ExcludeNodeSelector, &out.ExcludeNodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Assignment != nil {
+		in, out := &in.Assignment, &out.Assignment
+		*out = new(NodeAssignment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NotBefore != nil {
+		in, out := &in.NotBefore, &out.NotBefore
+		*out = (*in).DeepCopy()
+	}
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
 		*out = make(map[string]string, len(*in))
@@ -98,6 +344,32 @@ func (in *LabelsSpec) DeepCopyInto(out *LabelsSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Distributions != nil {
+		in, out := &in.Distributions, &out.Distributions
+		*out = make([]LabelDistribution, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = new(InventorySource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelsSpec.
@@ -113,6 +385,58 @@ func (in *LabelsSpec) DeepCopy() *LabelsSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LabelsStatus) DeepCopyInto(out *LabelsStatus) {
 	*out = *in
+	if in.MatchedNodes != nil {
+		in, out := &in.MatchedNodes, &out.MatchedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AssignedNodes != nil {
+		in, out := &in.AssignedNodes, &out.AssignedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Distributions != nil {
+		in, out := &in.Distributions, &out.Distributions
+		*out = make([]LabelDistributionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = make([]NodeInventory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Preview != nil {
+		in, out := &in.Preview, &out.Preview
+		*out = make([]NodeLabelsPreview, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppliedSpec != nil {
+		in, out := &in.AppliedSpec, &out.AppliedSpec
+		*out = new(LabelsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemovableNodes != nil {
+		in, out := &in.RemovableNodes, &out.RemovableNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextTransitionTime != nil {
+		in, out := &in.NextTransitionTime, &out.NextTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelsStatus.
@@ -125,13 +449,225 @@ func (in *LabelsStatus) DeepCopy() *LabelsStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAddressSelector) DeepCopyInto(out *NodeAddressSelector) {
+	*out = *in
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAddressSelector.
+func (in *NodeAddressSelector) DeepCopy() *NodeAddressSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAddressSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignment) DeepCopyInto(out *NodeAssignment) {
+	*out = *in
+	if in.MaxNodes != nil {
+		in, out := &in.MaxNodes, &out.MaxNodes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignment.
+func (in *NodeAssignment) DeepCopy() *NodeAssignment {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConditionRequirement) DeepCopyInto(out *NodeConditionRequirement) {
+	*out = *in
+	if in.MinDuration != nil {
+		in, out := &in.MinDuration, &out.MinDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConditionRequirement.
+func (in *NodeConditionRequirement) DeepCopy() *NodeConditionRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConditionRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFieldSelectorTerm) DeepCopyInto(out *NodeFieldSelectorTerm) {
+	*out = *in
+	if in.MatchFields != nil {
+		in, out := &in.MatchFields, &out.MatchFields
+		*out = make([]corev1.NodeSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFieldSelectorTerm.
+func (in *NodeFieldSelectorTerm) DeepCopy() *NodeFieldSelectorTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFieldSelectorTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeInventory) DeepCopyInto(out *NodeInventory) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeInventory.
+func (in *NodeInventory) DeepCopy() *NodeInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLabelsPreview) DeepCopyInto(out *NodeLabelsPreview) {
+	*out = *in
+	if in.WouldAdd != nil {
+		in, out := &in.WouldAdd, &out.WouldAdd
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.WouldChange != nil {
+		in, out := &in.WouldChange, &out.WouldChange
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.WouldRemove != nil {
+		in, out := &in.WouldRemove, &out.WouldRemove
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WouldAddAnnotations != nil {
+		in, out := &in.WouldAddAnnotations, &out.WouldAddAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.WouldRemoveAnnotations != nil {
+		in, out := &in.WouldRemoveAnnotations, &out.WouldRemoveAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WouldAddTaints != nil {
+		in, out := &in.WouldAddTaints, &out.WouldAddTaints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WouldRemoveTaints != nil {
+		in, out := &in.WouldRemoveTaints, &out.WouldRemoveTaints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLabelsPreview.
+func (in *NodeLabelsPreview) DeepCopy() *NodeLabelsPreview {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLabelsPreview)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMigration) DeepCopyInto(out *NodeMigration) {
+	*out = *in
+	if in.SourceLabels != nil {
+		in, out := &in.SourceLabels, &out.SourceLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMigration.
+func (in *NodeMigration) DeepCopy() *NodeMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeResourceRequirement) DeepCopyInto(out *NodeResourceRequirement) {
+	*out = *in
+	out.Value = in.Value.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeResourceRequirement.
+func (in *NodeResourceRequirement) DeepCopy() *NodeResourceRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeResourceRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OwnedLabels) DeepCopyInto(out *OwnedLabels) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnedLabels.
@@ -197,6 +733,21 @@ func (in *OwnedLabelsSpec) DeepCopyInto(out *OwnedLabelsSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AnnotationKeyPattern != nil {
+		in, out := &in.AnnotationKeyPattern, &out.AnnotationKeyPattern
+		*out = new(string)
+		**out = **in
+	}
+	if in.TaintKeyPattern != nil {
+		in, out := &in.TaintKeyPattern, &out.TaintKeyPattern
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxRemovals != nil {
+		in, out := &in.MaxRemovals, &out.MaxRemovals
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnedLabelsSpec.
@@ -212,6 +763,34 @@ func (in *OwnedLabelsSpec) DeepCopy() *OwnedLabelsSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OwnedLabelsStatus) DeepCopyInto(out *OwnedLabelsStatus) {
 	*out = *in
+	if in.MatchedNodes != nil {
+		in, out := &in.MatchedNodes, &out.MatchedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastRemovalTime != nil {
+		in, out := &in.LastRemovalTime, &out.LastRemovalTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Preview != nil {
+		in, out := &in.Preview, &out.Preview
+		*out = make([]NodeLabelsPreview, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RemovableNodes != nil {
+		in, out := &in.RemovableNodes, &out.RemovableNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnedLabelsStatus.
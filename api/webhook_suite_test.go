@@ -29,7 +29,9 @@ import (
 	. "github.com/onsi/gomega"
 
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -38,6 +40,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest/printer"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/openshift-kni/node-label-operator/api/v1beta1"
 	"github.com/openshift-kni/node-label-operator/pkg/test"
@@ -52,6 +55,9 @@ import (
 // These tests use Ginkgo (BDD-style Go testing framework). Refer to
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
 
+// operatorUsername is the username of the operator's service account, as it would be identified by the node webhooks
+const operatorUsername = "system:serviceaccount:node-label-operator:node-label-operator"
+
 var cfg *rest.Config
 var k8sClient client.Client
 var testEnv *envtest.Environment
@@ -75,7 +81,8 @@ var _ = BeforeSuite(func() {
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},
 		WebhookInstallOptions: envtest.WebhookInstallOptions{
-			Paths: []string{filepath.Join("..", "config", "webhook")},
+			Paths:            []string{filepath.Join("..", "config", "webhook")},
+			MutatingWebhooks: []client.Object{denyManagedEditsWebhook()},
 		},
 	}
 
@@ -112,7 +119,17 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	(&NodeLabeler{}).SetupWebhookWithManager(mgr)
+	(&NodeLabeler{OperatorUsername: operatorUsername}).SetupWebhookWithManager(mgr)
+	(&NodeValidator{ProtectLabels: true, OperatorUsername: operatorUsername}).SetupWebhookWithManager(mgr)
+	// the webhook for nodes with the deny-managed-edits test label, as with the --deny-managed-edits flag
+	mgr.GetWebhookServer().Register(denyManagedEditsPath, &webhook.Admission{Handler: &NodeLabeler{
+		Client:           mgr.GetClient(),
+		OperatorUsername: operatorUsername,
+		DenyManagedEdits: true,
+	}})
+	(&LabelsValidator{}).SetupWebhookWithManager(mgr)
+	(&OwnedLabelsValidator{}).SetupWebhookWithManager(mgr)
+	(&LabelMigrationValidator{}).SetupWebhookWithManager(mgr)
 
 	// +kubebuilder:scaffold:webhook
 
@@ -139,6 +156,47 @@ var _ = BeforeSuite(func() {
 
 }, 60)
 
+// denyManagedEditsPath is the path of the node webhook, which denies modifications of managed labels
+const denyManagedEditsPath = "/deny-managed-edits-v1-nodes"
+
+// denyManagedEditsWebhook returns a node webhook configuration, which denies modifications of managed labels on nodes
+// with the deny-managed-edits test label. Its name sorts before the one of the regular node webhook, so it is
+// called first, before managed labels are restored.
+func denyManagedEditsWebhook() *admissionregistrationv1.MutatingWebhookConfiguration {
+	path := denyManagedEditsPath
+	failurePolicy := admissionregistrationv1.Fail
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	return &admissionregistrationv1.MutatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MutatingWebhookConfiguration",
+			APIVersion: "admissionregistration.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "deny-managed-edits-webhook-configuration",
+		},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{{
+			Name: "mnode-deny.kb.io",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{Name: "webhook-service", Namespace: "system", Path: &path},
+			},
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Update},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"nodes"},
+				},
+			}},
+			ObjectSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{test.DenyManagedEditsLabel: "true"},
+			},
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1", "v1beta1"},
+		}},
+	}
+}
+
 var _ = AfterSuite(func() {
 	cancel()
 	By("tearing down the test environment")
@@ -0,0 +1,35 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"github.com/openshift-kni/node-label-operator/pkg"
+)
+
+// forgetCompiledHandler removes the compiled match expressions and patterns of deleted CRs from the cache.
+// It doesn't enqueue any requests.
+func forgetCompiledHandler() handler.EventHandler {
+	return handler.Funcs{
+		DeleteFunc: func(e event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+			pkg.ForgetCompiled(e.Object.GetUID())
+		},
+	}
+}
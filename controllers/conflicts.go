@@ -0,0 +1,53 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+)
+
+// conflictTracker remembers the label and annotation conflicts reported per node, so conflict events are only
+// recorded when a conflict appears, and not on every reconcile
+type conflictTracker struct {
+	lock      sync.Mutex
+	conflicts map[string]map[string]bool
+}
+
+// update replaces the conflicts of the given node with the given ones, and returns the conflicts which weren't
+// reported before
+func (t *conflictTracker) update(nodeName string, conflicts []string) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.conflicts == nil {
+		t.conflicts = map[string]map[string]bool{}
+	}
+	reported := t.conflicts[nodeName]
+	current := map[string]bool{}
+	var added []string
+	for _, conflict := range conflicts {
+		current[conflict] = true
+		if !reported[conflict] {
+			added = append(added, conflict)
+		}
+	}
+	if len(current) == 0 {
+		delete(t.conflicts, nodeName)
+	} else {
+		t.conflicts[nodeName] = current
+	}
+	return added
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+	"github.com/openshift-kni/node-label-operator/pkg"
+)
+
+// LabelMigrationReconciler migrates node labels of a LabelMigration object
+type LabelMigrationReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// DryRun disables all node modifications, the migration state is still reported in the status
+	DryRun bool
+	// MaxRemovals is the global limit of nodes which can lose owned labels at once. Owned source labels are only
+	// removed from the removable nodes of their OwnedLabels, if a limit applies.
+	MaxRemovals *intstr.IntOrString
+}
+
+// +kubebuilder:rbac:groups=node-labels.openshift.io,resources=labelmigrations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=node-labels.openshift.io,resources=labelmigrations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=node-labels.openshift.io,resources=labelmigrations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=node-labels.openshift.io,resources=labels,verbs=get;list;watch
+// +kubebuilder:rbac:groups=node-labels.openshift.io,resources=ownedlabels,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile migrates the source labels of a LabelMigration in two phases:
+// - copy the mapped value of the source labels to the target label on all matching nodes
+// - once every matching node has the target label, remove the source labels which are owned and uncovered, within
+//   the removal limits of their OwnedLabels
+// Owned source labels are kept by the NodeReconciler until the copy phase is done.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
+func (r *LabelMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("labelmigration", req.NamespacedName)
+
+	// get LabelMigration instance
+	migration := &v1beta1.LabelMigration{}
+	err := r.Get(ctx, req.NamespacedName, migration)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			log.Info("LabelMigration resource not found, ignoring because it must be deleted and we have nothing to do")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		log.Error(err, "Failed to get LabelMigration")
+		return ctrl.Result{}, err
+	}
+
+	if !migration.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	statusOrig := migration.Status.DeepCopy()
+	if err = pkg.ValidateLabelMigration(*migration); err != nil {
+		generation := migration.Generation
+		migration.Status.ObservedGeneration = generation
+		setCondition(&migration.Status.Conditions, generation, v1beta1.ConditionInvalidPattern, metav1.ConditionTrue, v1beta1.ReasonInvalidPattern, err.Error())
+		setCondition(&migration.Status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonInvalidPattern, "LabelMigration is invalid")
+		return ctrl.Result{}, r.updateStatus(ctx, migration, statusOrig, log)
+	}
+
+	// we need all Labels
+	allLabels := &v1beta1.LabelsList{}
+	if err = r.Client.List(ctx, allLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list Labels")
+		return ctrl.Result{}, err
+	}
+
+	// and OwnedLabels
+	ownedLabels := &v1beta1.OwnedLabelsList{}
+	if err = r.Client.List(ctx, ownedLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list OwnedLabels")
+		return ctrl.Result{}, err
+	}
+	liveOwnedLabels := pkg.LiveOwnedLabels(ownedLabels.Items)
+	limited := r.MaxRemovals != nil
+
+	// and nodes
+	nodes := &v1.NodeList{}
+	if err = r.Client.List(ctx, nodes, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list Nodes")
+		return ctrl.Result{}, err
+	}
+	nodes.Items = pkg.WithoutIgnoredNodes(nodes.Items)
+
+	// source labels are only removed once the target label is set on all nodes, so the removal starts with the next
+	// reconcile after the last copy
+	// use the resourceVersion as precondition, in order to not act on a stale node
+	phase := pkg.MigrationPhase(pkg.MigrationNodes(nodes.Items, *migration, liveOwnedLabels, allLabels.Items, r.DryRun, limited, log))
+	if !r.DryRun {
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			nodeOrig := node.DeepCopy()
+			nodeModified := false
+			switch phase {
+			case v1beta1.MigrationPhaseCopying:
+				nodeModified = pkg.CopyMigratedLabel(node, *migration, log)
+			case v1beta1.MigrationPhaseRemoving:
+				removableOwnedLabels := pkg.RemovableOwnedLabels(node.Name, liveOwnedLabels, r.MaxRemovals)
+				coveringLabels := pkg.CoveringLabels(node.Name, allLabels.Items, r.DryRun, limited)
+				nodeModified = pkg.RemoveMigratedLabels(node, *migration, removableOwnedLabels, coveringLabels, log)
+			}
+			if !nodeModified {
+				continue
+			}
+			log.Info("patching node labels", "node", node.Name, "phase", phase)
+			if err := r.Client.Patch(ctx, node, client.MergeFromWithOptions(nodeOrig, client.MergeFromWithOptimisticLock{})); err != nil {
+				log.Error(err, "Failed to patch Node", "node", node.Name)
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	// update status
+	r.setStatus(migration, pkg.MigrationNodes(nodes.Items, *migration, liveOwnedLabels, allLabels.Items, r.DryRun, limited, log))
+	if migration.Status.Phase != statusOrig.Phase && r.Recorder != nil {
+		r.Recorder.Event(migration, v1.EventTypeNormal, string(migration.Status.Phase), fmt.Sprintf("migration to label %s is in phase %s", migration.Spec.Target, migration.Status.Phase))
+	}
+	return ctrl.Result{}, r.updateStatus(ctx, migration, statusOrig, log)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LabelMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.LabelMigration{}).
+		Watches(&source.Kind{Type: &v1.Node{}}, handler.EnqueueRequestsFromMapFunc(r.labelMigrationsForNode), builder.WithPredicates(nodeChangedPredicate())).
+		Complete(r)
+}
+
+// labelMigrationsForNode maps a node to all LabelMigrations which aren't completed, because nodes can stop matching
+// a LabelMigration when their source labels are removed. Completed LabelMigrations are only mapped to nodes which
+// have source labels again, e.g. new nodes.
+func (r *LabelMigrationReconciler) labelMigrationsForNode(obj client.Object) []reconcile.Request {
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return nil
+	}
+	allMigrations := &v1beta1.LabelMigrationList{}
+	if err := r.Client.List(context.TODO(), allMigrations, &client.ListOptions{}); err != nil {
+		r.Log.Error(err, "Failed to list LabelMigrations")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, migration := range allMigrations.Items {
+		if migration.Status.Phase == v1beta1.MigrationPhaseCompleted && len(pkg.MigrationSourceLabels(node, migration, r.Log)) == 0 {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&migration)})
+	}
+	return requests
+}
+
+// setStatus updates the counts, the phase, the truncated nodes and the conditions of the given LabelMigration, based
+// on the given migration state of all its nodes
+func (r *LabelMigrationReconciler) setStatus(migration *v1beta1.LabelMigration, nodeMigrations []v1beta1.NodeMigration) {
+	generation := migration.Generation
+	status := &migration.Status
+	status.ObservedGeneration = generation
+	status.Phase = pkg.MigrationPhase(nodeMigrations)
+	status.Nodes = migrationNodesStatus(nodeMigrations)
+
+	var pending, conflicts int32
+	status.MatchedNodesCount, status.CopiedNodesCount, status.RemovedNodesCount = 0, 0, 0
+	for _, nodeMigration := range nodeMigrations {
+		status.MatchedNodesCount++
+		switch nodeMigration.State {
+		case v1beta1.NodeMigrationPending:
+			pending++
+		case v1beta1.NodeMigrationConflict:
+			conflicts++
+		case v1beta1.NodeMigrationRemoved:
+			status.CopiedNodesCount++
+			status.RemovedNodesCount++
+		default:
+			status.CopiedNodesCount++
+		}
+	}
+
+	setCondition(&status.Conditions, generation, v1beta1.ConditionInvalidPattern, metav1.ConditionFalse, v1beta1.ReasonValid, "")
+	switch {
+	case conflicts > 0:
+		setCondition(&status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonMigrationConflict,
+			fmt.Sprintf("label %s is set to another value, or source labels map to different values, on %d nodes", migration.Spec.Target, conflicts))
+	case r.DryRun && status.Phase != v1beta1.MigrationPhaseCompleted:
+		setCondition(&status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonDryRun, "dry-run mode, nodes aren't modified")
+	case pending > 0:
+		setCondition(&status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonProgressing,
+			fmt.Sprintf("label %s is not set yet on %d nodes", migration.Spec.Target, pending))
+	case status.Phase == v1beta1.MigrationPhaseRemoving:
+		setCondition(&status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonProgressing, "owned source labels are not removed yet")
+	default:
+		setCondition(&status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionTrue, v1beta1.ReasonApplied,
+			fmt.Sprintf("label %s is set on all %d migrated nodes", migration.Spec.Target, status.MatchedNodesCount))
+	}
+}
+
+// updateStatus updates the status of the given LabelMigration, if it differs from the given original status
+func (r *LabelMigrationReconciler) updateStatus(ctx context.Context, migration *v1beta1.LabelMigration, statusOrig *v1beta1.LabelMigrationStatus, log logr.Logger) error {
+	if equality.Semantic.DeepEqual(statusOrig, &migration.Status) {
+		return nil
+	}
+	log.Info("updating status")
+	if err := r.Status().Update(ctx, migration); err != nil {
+		log.Error(err, "Failed to update status")
+		return err
+	}
+	return nil
+}
@@ -18,33 +18,65 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/openshift-kni/node-label-operator/api/v1beta1"
 	"github.com/openshift-kni/node-label-operator/pkg"
 )
 
-const labelsFinalizer = "node-label-operator.openshift.io/finalizer"
+const (
+	labelsFinalizer = "node-label-operator.openshift.io/finalizer"
+
+	pendingRemovalsRequeueInterval = 5 * time.Second
+)
 
 // LabelsReconciler reconciles a Labels object
 type LabelsReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// DryRun previews the label changes of all Labels
+	DryRun bool
+	// MaxRemovals limits the number of nodes which can lose managed labels at once, per Labels
+	MaxRemovals *intstr.IntOrString
+	// InventoryNamespaces are the namespaces whose ConfigMaps can be used as inventory, all namespaces if empty.
+	// Only ConfigMaps in these namespaces are cached and watched.
+	InventoryNamespaces []string
+	// inventoryReader reads the inventory ConfigMaps
+	inventoryReader client.Reader
 }
 
 // +kubebuilder:rbac:groups=node-labels.openshift.io,resources=labels,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=node-labels.openshift.io,resources=labels/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=node-labels.openshift.io,resources=labels/finalizers,verbs=update
-// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch,namespace=system
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -88,73 +120,529 @@ func (r *LabelsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		}
 	}
 
-	// iterate all nodes
-	// we have to
-	// - remove all owned labels, if they aren't in any label rule
-	// - add labels of this instance
-
 	// we need all Labels
 	allLabels := &v1beta1.LabelsList{}
-	if err = r.Client.List(context.TODO(), allLabels, &client.ListOptions{}); err != nil {
+	if err = r.Client.List(ctx, allLabels, &client.ListOptions{}); err != nil {
 		log.Error(err, "Failed to list Labels")
 		return ctrl.Result{}, err
 	}
 
-	// and OwnedLabels
-	ownedLabels := &v1beta1.OwnedLabelsList{}
-	if err = r.Client.List(context.TODO(), ownedLabels, &client.ListOptions{}); err != nil {
-		log.Error(err, "Failed to list OwnedLabels")
-		return ctrl.Result{}, err
-	}
-
-	// get nodes
+	// and nodes
 	nodes := &v1.NodeList{}
-	if err = r.Client.List(context.TODO(), nodes, &client.ListOptions{}); err != nil {
+	if err = r.Client.List(ctx, nodes, &client.ListOptions{}); err != nil {
 		log.Error(err, "Failed to list Nodes")
 		return ctrl.Result{}, err
 	}
+	nodes.Items = pkg.WithoutIgnoredNodes(nodes.Items)
 
-	// and start
-	for _, nodeOrig := range nodes.Items {
-
-		log.Info("Processing node", "nodeName", nodeOrig.Name)
-
-		node := nodeOrig.DeepCopy()
-		nodeModified := pkg.RemoveOwnedLabels(node, ownedLabels.Items, allLabels.Items, log)
-
-		// owned labels are removed now on this node
-		// add new / modified labels
-		nodeModified = pkg.AddLabels(node, *labels, log) || nodeModified
+	// managed labels of the applied spec are removed by the NodeReconciler, as long as they don't exceed the
+	// removal limit
+	// in dry-run mode the NodeReconciler doesn't remove them, so there is nothing to check
+	var removalNodes []string
+	var blockedMessage string
+	if !r.DryRun && (markedForDeletion || !labels.Spec.DryRun) {
+		removalNodes, blockedMessage = pkg.BlockedLabels(nodes.Items, *labels, allLabels.Items, r.MaxRemovals, log)
+	}
 
-		// save node
-		if nodeModified {
-			log.Info("patching node")
-			baseToPatch := client.MergeFrom(&nodeOrig)
-			if err := r.Client.Patch(context.TODO(), node, baseToPatch); err != nil {
-				log.Error(err, "Failed to patch Node")
+	// labels are applied to nodes by the NodeReconciler
+	// on deletion we only have to wait until it removed our managed and owned labels from all nodes
+	// in global dry-run mode the NodeReconciler doesn't remove anything, so there is nothing to wait for
+	if markedForDeletion {
+		if !r.DryRun {
+			statusOrig := labels.Status.DeepCopy()
+			r.updateRemovalStatus(labels, statusOrig, removalNodes, blockedMessage)
+			if !equality.Semantic.DeepEqual(statusOrig, &labels.Status) {
+				log.Info("updating status")
+				if err = r.Status().Update(ctx, labels); err != nil {
+					log.Error(err, "Failed to update status")
+					return ctrl.Result{}, err
+				}
+			}
+			if blockedMessage != "" {
+				// the applied labels are kept until the removals are approved, which updates the Labels
+				log.Info("waiting for approval of removals")
+				return ctrl.Result{}, nil
+			}
+			pending, err := r.hasPendingRemovals(ctx, labels, allLabels.Items, nodes.Items, log)
+			if err != nil {
 				return ctrl.Result{}, err
 			}
+			if pending {
+				log.Info("waiting for managed and owned labels being removed from nodes")
+				return ctrl.Result{RequeueAfter: pendingRemovalsRequeueInterval}, nil
+			}
 		}
 
-	}
-
-	// remove finalizer
-	if markedForDeletion {
+		// remove finalizer
 		log.Info("removing finalizer")
 		controllerutil.RemoveFinalizer(labels, labelsFinalizer)
-		err := r.Update(ctx, labels)
+		err = r.Update(ctx, labels)
 		if err != nil {
 			log.Error(err, "Failed to remove finalizer")
 			return ctrl.Result{}, err
 		}
+		return ctrl.Result{}, nil
+	}
+
+	// and the inventory
+	inventoryRows, inventoryErr := r.readInventory(ctx, labels)
+	if inventoryErr != nil {
+		log.Error(inventoryErr, "Failed to read inventory")
+	}
+
+	// update status
+	statusOrig := labels.Status.DeepCopy()
+	r.updateRemovalStatus(labels, statusOrig, removalNodes, blockedMessage)
+	conflicts := r.updateStatus(labels, allLabels.Items, nodes.Items, inventoryRows, inventoryErr, len(removalNodes) > 0, log)
+	r.recordConflicts(labels, statusOrig, conflicts)
+	recordPreviews(r.Recorder, labels, statusOrig.Preview, labels.Status.Preview)
+	if !equality.Semantic.DeepEqual(statusOrig, &labels.Status) {
+		log.Info("updating status")
+		if err = r.Status().Update(ctx, labels); err != nil {
+			log.Error(err, "Failed to update status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// the approval is only valid for a single removal pass
+	if pkg.IsRemovalApproved(labels) && len(removalNodes) == 0 && !r.DryRun && !labels.Spec.DryRun {
+		log.Info("removing approval of removals")
+		labelsOrig := labels.DeepCopy()
+		delete(labels.Annotations, v1beta1.AnnotationApproveRemovals)
+		if err = r.Patch(ctx, labels, client.MergeFrom(labelsOrig)); err != nil {
+			log.Error(err, "Failed to remove approval of removals")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// matching nodes change without node update when node conditions reach their minimum duration, or when the
+	// Labels is activated or expires
+	now := time.Now()
+	next := pkg.NextScheduleTransition([]v1beta1.Labels{*labels}, now)
+	for i := range nodes.Items {
+		if after := pkg.NextConditionMatch(&nodes.Items[i], []v1beta1.Labels{*labels}, now); after > 0 && (next == 0 || after < next) {
+			next = after
+		}
+	}
+	if next > 0 {
+		log.Info("Requeueing for pending node condition requirement or schedule transition", "after", next)
+		return ctrl.Result{RequeueAfter: next}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// With inventory namespaces, ConfigMaps are cached by an own cache for these namespaces only, so the operator
+// doesn't need to read ConfigMaps of all namespaces.
 func (r *LabelsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	var configMapSource source.Source = &source.Kind{Type: &v1.ConfigMap{}}
+	r.inventoryReader = mgr.GetClient()
+	if len(r.InventoryNamespaces) > 0 {
+		configMapCache, err := cache.MultiNamespacedCacheBuilder(r.InventoryNamespaces)(mgr.GetConfig(),
+			cache.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		if err != nil {
+			return err
+		}
+		if err = mgr.Add(configMapCache); err != nil {
+			return err
+		}
+		configMapSource = source.NewKindWithCache(&v1.ConfigMap{}, configMapCache)
+		r.inventoryReader = configMapCache
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.Labels{}).
+		Watches(&source.Kind{Type: &v1.Node{}}, r.nodeEventHandler(), builder.WithPredicates(nodeChangedPredicate())).
+		Watches(configMapSource, handler.EnqueueRequestsFromMapFunc(r.labelsForConfigMap)).
+		Watches(&source.Kind{Type: &v1beta1.Labels{}}, handler.EnqueueRequestsFromMapFunc(r.labelsForLabels)).
+		Watches(&source.Kind{Type: &v1beta1.Labels{}}, forgetCompiledHandler()).
 		Complete(r)
 }
+
+// nodeEventHandler maps node events to the Labels whose status can be changed by them
+func (r *LabelsReconciler) nodeEventHandler() handler.EventHandler {
+	enqueue := func(q workqueue.RateLimitingInterface, requests []reconcile.Request) {
+		for _, request := range requests {
+			q.Add(request)
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			enqueue(q, r.labelsForNode(nil, e.Object))
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			enqueue(q, r.labelsForNode(e.ObjectOld, e.ObjectNew))
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			enqueue(q, r.labelsForNode(nil, e.Object))
+		},
+		GenericFunc: func(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+			enqueue(q, r.labelsForNode(nil, e.Object))
+		},
+	}
+}
+
+// labelsForNode maps a node to the Labels matching it with their current or their applied spec
+// For updates only Labels are mapped, which match the old or the new node, and for which either the match result
+// changed, or the labels, annotations or taints they set on the node changed. Other node updates can't change
+// their status.
+func (r *LabelsReconciler) labelsForNode(oldObj, obj client.Object) []reconcile.Request {
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return nil
+	}
+	oldNode, _ := oldObj.(*v1.Node)
+	log := r.Log.WithValues("node", node.Name)
+
+	allLabels := &v1beta1.LabelsList{}
+	if err := r.Client.List(context.TODO(), allLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list Labels")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, labels := range allLabels.Items {
+		// the applied spec is tracked until its uncovered labels are removed from all nodes
+		specs := []v1beta1.Labels{labels}
+		if applied := pkg.AppliedLabels(labels); applied != nil {
+			specs = append(specs, *applied)
+		}
+		for _, spec := range specs {
+			if labelsNodeChanged(oldNode, node, spec, log) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&labels)})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// labelsNodeChanged checks if the given node matches the given Labels, and for updates with an old node, if the
+// match result or the state of the Labels on the node changed
+func labelsNodeChanged(oldNode, node *v1.Node, labels v1beta1.Labels, log logr.Logger) bool {
+	// matching nodes which aren't assigned yet might need to be assigned
+	matches := pkg.MatchesNodeSelection(node, labels, log)
+	if oldNode == nil {
+		return matches
+	}
+	matchedBefore := pkg.MatchesNodeSelection(oldNode, labels, log)
+	if !matches && !matchedBefore {
+		return false
+	}
+	return matches != matchedBefore || labelsStateChanged(oldNode, node, labels, log)
+}
+
+// labelsStateChanged checks if any label, annotation or taint of the given Labels changed between the old and the
+// new node
+func labelsStateChanged(oldNode, node *v1.Node, labels v1beta1.Labels, log logr.Logger) bool {
+	for name := range pkg.LabelsForNode(node, labels, log) {
+		oldValue, hadLabel := oldNode.Labels[name]
+		value, hasLabel := node.Labels[name]
+		if hadLabel != hasLabel || oldValue != value {
+			return true
+		}
+	}
+	for name := range pkg.AnnotationsForNode(node, labels, log) {
+		oldValue, hadAnnotation := oldNode.Annotations[name]
+		value, hasAnnotation := node.Annotations[name]
+		if hadAnnotation != hasAnnotation || oldValue != value {
+			return true
+		}
+	}
+	for _, taint := range pkg.TaintsForNode(node, labels, log) {
+		if pkg.HasTaint(oldNode, taint) != pkg.HasTaint(node, taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// labelsForLabels maps a Labels to the other Labels depending on it or it depends on, and to the Labels reporting a
+// dependency cycle, since a changed Labels can create or break cycles
+func (r *LabelsReconciler) labelsForLabels(obj client.Object) []reconcile.Request {
+	changedLabels, ok := obj.(*v1beta1.Labels)
+	if !ok {
+		return nil
+	}
+	log := r.Log.WithValues("labels", client.ObjectKeyFromObject(obj))
+
+	allLabels := &v1beta1.LabelsList{}
+	if err := r.Client.List(context.TODO(), allLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list Labels")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, labels := range allLabels.Items {
+		if labels.Namespace == changedLabels.Namespace && labels.Name == changedLabels.Name {
+			continue
+		}
+		if pkg.IsInDependencyCycle(labels) || pkg.DependsOn(labels, *changedLabels) || pkg.DependsOn(*changedLabels, labels) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&labels)})
+		}
+	}
+	return requests
+}
+
+// labelsForConfigMap maps a ConfigMap to the Labels using it as inventory
+func (r *LabelsReconciler) labelsForConfigMap(obj client.Object) []reconcile.Request {
+	log := r.Log.WithValues("configMap", client.ObjectKeyFromObject(obj))
+
+	allLabels := &v1beta1.LabelsList{}
+	if err := r.Client.List(context.TODO(), allLabels, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.Error(err, "Failed to list Labels")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, labels := range allLabels.Items {
+		if labels.Spec.Inventory != nil && labels.Spec.Inventory.ConfigMapName == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&labels)})
+		}
+	}
+	return requests
+}
+
+// readInventory reads and parses the inventory table of the given Labels, if it has an inventory
+func (r *LabelsReconciler) readInventory(ctx context.Context, labels *v1beta1.Labels) ([]map[string]string, error) {
+	source := labels.Spec.Inventory
+	if source == nil {
+		return nil, nil
+	}
+	if len(r.InventoryNamespaces) > 0 && !sets.NewString(r.InventoryNamespaces...).Has(labels.Namespace) {
+		return nil, fmt.Errorf("inventories can only be read from the namespaces %s", strings.Join(r.InventoryNamespaces, ", "))
+	}
+	configMap := &v1.ConfigMap{}
+	if err := r.inventoryReader.Get(ctx, client.ObjectKey{Namespace: labels.Namespace, Name: source.ConfigMapName}, configMap); err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s: %v", source.ConfigMapName, err)
+	}
+	data, ok := configMap.Data[source.Key]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in ConfigMap %s", source.Key, source.ConfigMapName)
+	}
+	return pkg.ParseInventory(data, *source)
+}
+
+// updateScheduleStatus updates the next transition time and the Active condition of the given Labels, if it has
+// a time window
+func (r *LabelsReconciler) updateScheduleStatus(labels *v1beta1.Labels) {
+	if labels.Spec.NotBefore == nil && labels.Spec.NotAfter == nil && labels.Spec.TTL == nil {
+		labels.Status.NextTransitionTime = nil
+		meta.RemoveStatusCondition(&labels.Status.Conditions, v1beta1.ConditionActive)
+		return
+	}
+
+	now := time.Now()
+	labels.Status.NextTransitionTime = nil
+	next := pkg.NextTransition(*labels, now)
+	if !next.IsZero() {
+		nextTransitionTime := metav1.NewTime(next)
+		labels.Status.NextTransitionTime = &nextTransitionTime
+	}
+	switch {
+	case pkg.IsActive(*labels, now):
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionActive, metav1.ConditionTrue, v1beta1.ReasonActive, "")
+	case pkg.IsExpired(*labels, now):
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionActive, metav1.ConditionFalse, v1beta1.ReasonExpired, "Labels expired and isn't applied anymore")
+	default:
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionActive, metav1.ConditionFalse, v1beta1.ReasonScheduled,
+			fmt.Sprintf("Labels isn't applied before %s", next.UTC().Format(time.RFC3339)))
+	}
+}
+
+// updateRemovalStatus updates the RemovalBlocked condition and the removable nodes of the given Labels, based on the
+// given nodes losing managed labels of its applied spec, and emits an event when the removals are blocked
+func (r *LabelsReconciler) updateRemovalStatus(labels *v1beta1.Labels, statusOrig *v1beta1.LabelsStatus, removalNodes []string, blockedMessage string) {
+	if blockedMessage != "" {
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionRemovalBlocked, metav1.ConditionTrue, v1beta1.ReasonRemovalLimitExceeded,
+			fmt.Sprintf("%s, set the %s annotation to \"true\" for approving the removals", blockedMessage, v1beta1.AnnotationApproveRemovals))
+		if !meta.IsStatusConditionTrue(statusOrig.Conditions, v1beta1.ConditionRemovalBlocked) && r.Recorder != nil {
+			r.Recorder.Event(labels, v1.EventTypeWarning, v1beta1.ReasonRemovalLimitExceeded, blockedMessage)
+		}
+	} else {
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionRemovalBlocked, metav1.ConditionFalse, v1beta1.ReasonRemovalAllowed, "")
+	}
+
+	// without limit or with approved removals all nodes are removable anyway
+	if r.MaxRemovals != nil && blockedMessage == "" && !pkg.IsRemovalApproved(labels) {
+		labels.Status.RemovableNodes = removalNodes
+	} else {
+		labels.Status.RemovableNodes = nil
+	}
+}
+
+// updateStatus updates the status of the given Labels, based on the given nodes and inventory rows, and returns the
+// found conflicts
+// The applied spec is only updated when no node has pending removals of managed labels of the previously applied
+// spec anymore, so it keeps covering them until then.
+func (r *LabelsReconciler) updateStatus(labels *v1beta1.Labels, allLabels []v1beta1.Labels, nodes []v1.Node,
+	inventoryRows []map[string]string, inventoryErr error, pendingRemovals bool, log logr.Logger) []pkg.LabelConflict {
+
+	labels.Status.ObservedGeneration = labels.Generation
+	// the dependency cycle, assignment, distributions and inventory are used by all following node matching
+	if cycle, inCycle := pkg.DependencyCycles(allLabels)[client.ObjectKeyFromObject(labels)]; inCycle {
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionDependencyCycle, metav1.ConditionTrue, v1beta1.ReasonDependencyCycle,
+			fmt.Sprintf("Labels select nodes by labels set by each other: %s", strings.Join(cycle, ", ")))
+	} else {
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionDependencyCycle, metav1.ConditionFalse, v1beta1.ReasonNoDependencyCycle, "")
+	}
+	r.updateScheduleStatus(labels)
+	labels.Status.AssignedNodes = pkg.AssignNodes(nodes, *labels, log)
+	labels.Status.Distributions = pkg.DistributeValues(nodes, *labels, log)
+	switch {
+	case labels.Spec.Inventory == nil:
+		labels.Status.Inventory = nil
+		meta.RemoveStatusCondition(&labels.Status.Conditions, v1beta1.ConditionInventoryFailed)
+	case inventoryErr != nil:
+		// keep the last known inventory labels, in order to not remove them because of a temporary error
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionInventoryFailed, metav1.ConditionTrue, v1beta1.ReasonInventoryFailed, inventoryErr.Error())
+	default:
+		labels.Status.Inventory = pkg.InventoryStatus(nodes, *labels, inventoryRows, log)
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionInventoryFailed, metav1.ConditionFalse, v1beta1.ReasonInventoryRead, "")
+	}
+
+	dryRun := r.DryRun || labels.Spec.DryRun
+	var matchedNodes []string
+	var targetNodes int
+	var previews []v1beta1.NodeLabelsPreview
+	var conflicts []pkg.LabelConflict
+	for i, node := range nodes {
+		matches := pkg.MatchesNodeSelection(&nodes[i], *labels, log)
+		if matches {
+			matchedNodes = append(matchedNodes, node.Name)
+		}
+		targeted := matches && pkg.IsAssigned(&nodes[i], *labels)
+		if targeted {
+			targetNodes++
+			conflicts = append(conflicts, pkg.FindConflicts(&nodes[i], *labels, allLabels, log)...)
+		} else if !dryRun {
+			continue
+		}
+		// in dry-run mode nodes which aren't targeted anymore can still lose labels of the applied spec
+		if preview := pkg.PreviewLabels(&nodes[i], *labels, allLabels, r.DryRun, log); preview != nil {
+			previews = append(previews, *preview)
+		}
+	}
+	labels.Status.MatchedNodesCount, labels.Status.MatchedNodes = matchedNodesStatus(matchedNodes)
+	pendingNodes := len(previews)
+
+	if dryRun {
+		labels.Status.Preview = previewStatus(previews)
+	} else {
+		labels.Status.Preview = nil
+		if !pendingRemovals {
+			labels.Status.AppliedSpec = labels.Spec.DeepCopy()
+		}
+	}
+
+	if err := pkg.ValidateLabels(*labels); err != nil {
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionInvalidPattern, metav1.ConditionTrue, v1beta1.ReasonInvalidPattern, err.Error())
+	} else {
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionInvalidPattern, metav1.ConditionFalse, v1beta1.ReasonValid, "")
+	}
+
+	expressionErr := pkg.MatchExpressionError(nodes, labels, labels.Spec.MatchExpression)
+	switch {
+	case labels.Spec.MatchExpression == "":
+		meta.RemoveStatusCondition(&labels.Status.Conditions, v1beta1.ConditionExpressionFailed)
+	case expressionErr != nil:
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionExpressionFailed, metav1.ConditionTrue, v1beta1.ReasonExpressionFailed, expressionErr.Error())
+	default:
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionExpressionFailed, metav1.ConditionFalse, v1beta1.ReasonExpressionEvaluated, "")
+	}
+
+	if len(conflicts) > 0 {
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionConflicting, metav1.ConditionTrue, v1beta1.ReasonConflicting, conflictsMessage(conflicts))
+	} else {
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionConflicting, metav1.ConditionFalse, v1beta1.ReasonNoConflicts, "")
+	}
+
+	switch {
+	case meta.IsStatusConditionTrue(labels.Status.Conditions, v1beta1.ConditionInvalidPattern):
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonInvalidPattern, "Labels is invalid")
+	case meta.IsStatusConditionTrue(labels.Status.Conditions, v1beta1.ConditionDependencyCycle):
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonDependencyCycle, "Labels is in a dependency cycle and isn't applied")
+	case meta.IsStatusConditionFalse(labels.Status.Conditions, v1beta1.ConditionActive):
+		active := meta.FindStatusCondition(labels.Status.Conditions, v1beta1.ConditionActive)
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionReady, metav1.ConditionFalse, active.Reason, active.Message)
+	case meta.IsStatusConditionTrue(labels.Status.Conditions, v1beta1.ConditionInventoryFailed):
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonInventoryFailed, "inventory can't be read")
+	case meta.IsStatusConditionTrue(labels.Status.Conditions, v1beta1.ConditionExpressionFailed):
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonExpressionFailed, "match expression can't be evaluated for all nodes")
+	case len(conflicts) > 0:
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonConflicting, "Labels is overridden by other Labels with higher precedence")
+	case meta.IsStatusConditionTrue(labels.Status.Conditions, v1beta1.ConditionRemovalBlocked):
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonRemovalLimitExceeded, "removal of uncovered managed labels is blocked")
+	case dryRun && pendingNodes > 0:
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonDryRun, fmt.Sprintf("dry-run mode, labels would be changed on %d nodes", pendingNodes))
+	case pendingNodes > 0:
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonProgressing, fmt.Sprintf("%d of %d matching nodes are not labeled yet", pendingNodes, targetNodes))
+	default:
+		setCondition(&labels.Status.Conditions, labels.Generation, v1beta1.ConditionReady, metav1.ConditionTrue, v1beta1.ReasonApplied, fmt.Sprintf("%d matching nodes are labeled", targetNodes))
+	}
+	return conflicts
+}
+
+// recordConflicts emits a warning event for each of the given conflicts, if they changed since the last status update
+func (r *LabelsReconciler) recordConflicts(labels *v1beta1.Labels, statusOrig *v1beta1.LabelsStatus, conflicts []pkg.LabelConflict) {
+	if r.Recorder == nil || len(conflicts) == 0 {
+		return
+	}
+	conflictingOrig := meta.FindStatusCondition(statusOrig.Conditions, v1beta1.ConditionConflicting)
+	conflicting := meta.FindStatusCondition(labels.Status.Conditions, v1beta1.ConditionConflicting)
+	if conflictingOrig != nil && conflicting != nil && conflictingOrig.Status == conflicting.Status && conflictingOrig.Message == conflicting.Message {
+		return
+	}
+	for _, conflict := range conflicts {
+		r.Recorder.Event(labels, v1.EventTypeWarning, v1beta1.ReasonConflicting, conflictMessage(conflict))
+	}
+}
+
+// hasPendingRemovals checks if any node still has a managed or owned label or an owned taint of the given Labels,
+// which is removed by the NodeReconciler because it isn't covered anymore by the given Labels. Owned labels which
+// are kept by a LabelMigration aren't pending.
+func (r *LabelsReconciler) hasPendingRemovals(ctx context.Context, labels *v1beta1.Labels, allLabels []v1beta1.Labels, nodes []v1.Node, log logr.Logger) (bool, error) {
+	ownedLabels := &v1beta1.OwnedLabelsList{}
+	if err := r.Client.List(ctx, ownedLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list OwnedLabels")
+		return false, err
+	}
+	migrations := &v1beta1.LabelMigrationList{}
+	if err := r.Client.List(ctx, migrations, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list LabelMigrations")
+		return false, err
+	}
+
+	for i, node := range nodes {
+		if pkg.IsProtectionBypassed(&nodes[i]) {
+			// not modified by the NodeReconciler
+			continue
+		}
+		nodeCopy := node.DeepCopy()
+		migratingLabels := pkg.MigratingLabels(&nodes[i], migrations.Items, log)
+		if !pkg.RemoveUncovered(nodeCopy, allLabels, ownedLabels.Items, r.MaxRemovals, migratingLabels, log) {
+			continue
+		}
+		for name := range pkg.LabelsForNode(&nodes[i], *labels, log) {
+			_, hadLabel := node.Labels[name]
+			_, hasLabel := nodeCopy.Labels[name]
+			if hadLabel && !hasLabel {
+				return true, nil
+			}
+		}
+		for name := range pkg.AnnotationsForNode(&nodes[i], *labels, log) {
+			_, hadAnnotation := node.Annotations[name]
+			_, hasAnnotation := nodeCopy.Annotations[name]
+			if hadAnnotation && !hasAnnotation {
+				return true, nil
+			}
+		}
+		for _, taint := range pkg.TaintsForNode(&nodes[i], *labels, log) {
+			if pkg.HasTaint(&nodes[i], taint) && !pkg.HasTaint(nodeCopy, taint) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
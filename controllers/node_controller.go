@@ -0,0 +1,542 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+	"github.com/openshift-kni/node-label-operator/pkg"
+)
+
+// FieldManager is the field manager used for applying labels to nodes
+const FieldManager = "node-label-operator"
+
+// NodeReconciler reconciles the labels of a Node, based on all Labels and OwnedLabels
+type NodeReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// RemovedLabels counts the removed owned labels for the OwnedLabels status
+	RemovedLabels *RemovedLabelsCounter
+	// ForceOwnership takes over labels which were already set to another value by other tools
+	ForceOwnership bool
+	// DryRun disables all node modifications, changes are previewed by the Labels and OwnedLabels reconcilers
+	DryRun bool
+	// MaxRemovals limits the number of nodes which can lose owned labels at once, over all OwnedLabels, and the
+	// number of nodes which can lose managed labels at once, per Labels
+	MaxRemovals *intstr.IntOrString
+	// conflicts are the reported label and annotation conflicts
+	conflicts conflictTracker
+}
+
+// +kubebuilder:rbac:groups=node-labels.openshift.io,resources=labels,verbs=get;list;watch
+// +kubebuilder:rbac:groups=node-labels.openshift.io,resources=ownedlabels,verbs=get;list;watch
+// +kubebuilder:rbac:groups=node-labels.openshift.io,resources=labelmigrations,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile computes the complete desired set of labels, annotations and taints of a single node in one pass:
+// - remove all managed and owned labels and annotations, and all owned taints, if they aren't in any label rule
+// - add taints of all matching label rules
+// - apply labels and annotations of all matching label rules, and record them as managed labels and annotations
+// Labels and OwnedLabels in dry-run mode don't add, modify or remove labels. Labels in dry-run mode still
+// protect covered labels from being removed though, with both their current and their applied spec.
+// Labels and OwnedLabels which would remove labels from more nodes than allowed are skipped until the removals are
+// approved. With removal limits, labels are only removed from nodes which are published as removable in the status
+// of the Labels and OwnedLabels, after their reconcilers checked the removals against the limits.
+// Owned source labels of LabelMigrations are kept until the target label is copied to all nodes, owned target labels
+// as long as the LabelMigration exists.
+// Nodes with the allow-edits or the ignore annotation aren't modified at all.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
+func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("node", req.Name)
+
+	log.Info("Reconciling")
+
+	if r.DryRun {
+		log.Info("Dry-run mode, not modifying node")
+		return ctrl.Result{}, nil
+	}
+
+	// get Node instance
+	nodeOrig := &v1.Node{}
+	err := r.Get(ctx, req.NamespacedName, nodeOrig)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			log.Info("Node not found, ignoring because it must be deleted")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		log.Error(err, "Failed to get Node")
+		return ctrl.Result{}, err
+	}
+
+	if pkg.IsProtectionBypassed(nodeOrig) {
+		log.Info("Protection bypassed by annotation, not modifying node", "annotation", v1beta1.AnnotationAllowEdits)
+		return ctrl.Result{}, nil
+	}
+	if pkg.IsIgnored(nodeOrig) {
+		log.Info("Node is ignored by annotation, not modifying node", "annotation", v1beta1.AnnotationIgnore)
+		return ctrl.Result{}, nil
+	}
+
+	// we need all Labels
+	allLabels := &v1beta1.LabelsList{}
+	if err = r.Client.List(ctx, allLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list Labels")
+		return ctrl.Result{}, err
+	}
+
+	// and OwnedLabels
+	ownedLabels := &v1beta1.OwnedLabelsList{}
+	if err = r.Client.List(ctx, ownedLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list OwnedLabels")
+		return ctrl.Result{}, err
+	}
+
+	// and LabelMigrations
+	migrations := &v1beta1.LabelMigrationList{}
+	if err = r.Client.List(ctx, migrations, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list LabelMigrations")
+		return ctrl.Result{}, err
+	}
+
+	// remove uncovered labels, annotations and taints, and add taints
+	// owned labels and annotations were set by other field managers, so they can't be removed by an apply patch
+	// taints are an atomic list, which can't be shared with other field managers
+	// use the resourceVersion as precondition, in order to not act on a stale node
+	node := nodeOrig.DeepCopy()
+	liveLabels := pkg.LiveLabels(allLabels.Items)
+	migratingLabels := pkg.MigratingLabels(node, migrations.Items, log)
+	nodeModified := pkg.RemoveUncovered(node, allLabels.Items, ownedLabels.Items, r.MaxRemovals, migratingLabels, log)
+	nodeModified = pkg.AddAllTaints(node, liveLabels, log) || nodeModified
+	if nodeModified {
+		log.Info("patching node labels, annotations and taints")
+		if err := r.Client.Patch(ctx, node, client.MergeFromWithOptions(nodeOrig, client.MergeFromWithOptimisticLock{})); err != nil {
+			log.Error(err, "Failed to patch Node")
+			return ctrl.Result{}, err
+		}
+		r.countRemovedLabels(nodeOrig, node, ownedLabels.Items, log)
+	}
+
+	// managed and owned labels and annotations are removed now on this node
+	// apply new / modified labels and annotations
+	desiredLabels := pkg.DesiredLabels(node, liveLabels, log)
+	desiredAnnotations := pkg.DesiredAnnotations(node, liveLabels, log)
+	conflicts := map[string]string{}
+	if !r.ForceOwnership {
+		for _, name := range pkg.ForeignLabels(node, desiredLabels) {
+			log.Info("Not overriding label set by another field manager", "labelName", name)
+			conflicts["label "+name] = fmt.Sprintf("label %s is already set to %s by another field manager, not overriding it with %s",
+				name, node.Labels[name], desiredLabels[name])
+			delete(desiredLabels, name)
+		}
+		for _, name := range pkg.ForeignAnnotations(node, desiredAnnotations) {
+			log.Info("Not overriding annotation set by another field manager", "annotationName", name)
+			conflicts["annotation "+name] = fmt.Sprintf("annotation %s is already set by another field manager, not overriding it", name)
+			delete(desiredAnnotations, name)
+		}
+	}
+	r.recordConflicts(node, conflicts)
+	nodeApplied := node.DeepCopy()
+	labelsModified := pkg.ApplyLabels(nodeApplied, desiredLabels, log)
+	annotationsModified := pkg.ApplyAnnotations(nodeApplied, desiredAnnotations, log)
+	if labelsModified || annotationsModified {
+		if !r.ForceOwnership {
+			if err := r.updateManagedValues(ctx, node, nodeApplied); err != nil {
+				log.Error(err, "Failed to update managed labels and annotations of Node")
+				return ctrl.Result{}, err
+			}
+		}
+		log.Info("applying labels and annotations to node")
+		if err := r.apply(ctx, nodeApplied); err != nil {
+			log.Error(err, "Failed to apply labels and annotations to Node")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// node conditions which have the required status, but not for long enough yet, and Labels which are activated
+	// or expire, don't cause a node update
+	now := time.Now()
+	next := pkg.NextConditionMatch(node, liveLabels, now)
+	if transition := pkg.NextScheduleTransition(liveLabels, now); transition > 0 && (next == 0 || transition < next) {
+		next = transition
+	}
+	if next > 0 {
+		log.Info("Requeueing for pending node condition requirement or schedule transition", "after", next)
+		return ctrl.Result{RequeueAfter: next}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// apply applies the managed labels and annotations of the given node with server-side apply, together with the
+// annotations recording them. Labels and annotations which were applied before, but aren't managed anymore, are
+// removed by the API server.
+// Ownership of labels and annotations set by other field managers is only taken over with ForceOwnership.
+func (r *NodeReconciler) apply(ctx context.Context, node *v1.Node) error {
+	labels := map[string]string{}
+	for _, name := range pkg.GetManagedLabels(node) {
+		if value, ok := node.Labels[name]; ok {
+			labels[name] = value
+		}
+	}
+	annotations := map[string]string{}
+	for _, name := range pkg.GetManagedAnnotations(node) {
+		if value, ok := node.Annotations[name]; ok {
+			annotations[name] = value
+		}
+	}
+	for _, name := range []string{v1beta1.AnnotationManagedLabels, v1beta1.AnnotationManagedAnnotations} {
+		if value, ok := node.Annotations[name]; ok {
+			annotations[name] = value
+		}
+	}
+	applyNode := &unstructured.Unstructured{}
+	applyNode.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Node"))
+	applyNode.SetName(node.Name)
+	applyNode.SetLabels(labels)
+	applyNode.SetAnnotations(annotations)
+	opts := []client.PatchOption{client.FieldOwner(FieldManager)}
+	if r.ForceOwnership {
+		opts = append(opts, client.ForceOwnership)
+	}
+	return r.Client.Patch(ctx, applyNode, client.Apply, opts...)
+}
+
+// updateManagedValues writes the new values of already managed labels and annotations of the given applied node with
+// an update, before they are applied without ForceOwnership. Managed labels and annotations can be owned by other
+// field managers, e.g. by the kubelet for labels added by the node webhook, or by users modifying them. Updates take
+// over their ownership without conflicts, since they are managed by the operator anyway.
+// Use the resourceVersion as precondition, in order to not act on a stale node.
+func (r *NodeReconciler) updateManagedValues(ctx context.Context, node, nodeApplied *v1.Node) error {
+	nodeUpdated := node.DeepCopy()
+	modified := false
+	for _, name := range pkg.GetManagedLabels(node) {
+		value, ok := nodeApplied.Labels[name]
+		if oldValue, hadLabel := node.Labels[name]; ok && hadLabel && oldValue != value {
+			nodeUpdated.Labels[name] = value
+			modified = true
+		}
+	}
+	for _, name := range pkg.GetManagedAnnotations(node) {
+		value, ok := nodeApplied.Annotations[name]
+		if oldValue, hadAnnotation := node.Annotations[name]; ok && hadAnnotation && oldValue != value {
+			nodeUpdated.Annotations[name] = value
+			modified = true
+		}
+	}
+	if !modified {
+		return nil
+	}
+	return r.Client.Patch(ctx, nodeUpdated, client.MergeFromWithOptions(node, client.MergeFromWithOptimisticLock{}), client.FieldOwner(FieldManager))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.Node{}, builder.WithPredicates(predicate.Or(nodeChangedPredicate(), r.nodeAnnotationsChangedPredicate()), predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// nothing to do for deleted nodes
+				return false
+			},
+		})).
+		Watches(&source.Kind{Type: &v1beta1.Labels{}}, handler.EnqueueRequestsFromMapFunc(r.nodesForLabels)).
+		Watches(&source.Kind{Type: &v1beta1.OwnedLabels{}}, handler.EnqueueRequestsFromMapFunc(r.nodesForOwnedLabels)).
+		Watches(&source.Kind{Type: &v1beta1.LabelMigration{}}, handler.EnqueueRequestsFromMapFunc(r.nodesForLabelMigration)).
+		Complete(r)
+}
+
+// nodeChangedPredicate filters node updates which can't change the desired labels and taints of a node
+func nodeChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, okOld := e.ObjectOld.(*v1.Node)
+			newNode, okNew := e.ObjectNew.(*v1.Node)
+			if !okOld || !okNew {
+				return true
+			}
+			// the whole spec is compared, since match expressions can use any field, e.g. unschedulable, and it
+			// changes rarely
+			return !reflect.DeepEqual(oldNode.Labels, newNode.Labels) ||
+				!reflect.DeepEqual(oldNode.Spec, newNode.Spec) ||
+				pkg.IsIgnored(oldNode) != pkg.IsIgnored(newNode) ||
+				oldNode.Status.NodeInfo != newNode.Status.NodeInfo ||
+				!reflect.DeepEqual(oldNode.Status.Addresses, newNode.Status.Addresses) ||
+				!resourcesEqual(oldNode.Status.Capacity, newNode.Status.Capacity) ||
+				!resourcesEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable) ||
+				!conditionsEqual(oldNode.Status.Conditions, newNode.Status.Conditions)
+		},
+	}
+}
+
+// conditionsEqual checks if the given node conditions have the same types, statuses and transition times, so
+// heartbeats of the kubelet are filtered
+func conditionsEqual(oldConditions, newConditions []v1.NodeCondition) bool {
+	if len(oldConditions) != len(newConditions) {
+		return false
+	}
+	for i := range oldConditions {
+		if oldConditions[i].Type != newConditions[i].Type ||
+			oldConditions[i].Status != newConditions[i].Status ||
+			!oldConditions[i].LastTransitionTime.Equal(&newConditions[i].LastTransitionTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// resourcesEqual checks if the given resource lists contain the same quantities. Quantities are compared by value,
+// so status updates which only change the representation of a quantity are filtered.
+func resourcesEqual(oldResources, newResources v1.ResourceList) bool {
+	if len(oldResources) != len(newResources) {
+		return false
+	}
+	for name, oldQuantity := range oldResources {
+		newQuantity, ok := newResources[name]
+		if !ok || oldQuantity.Cmp(newQuantity) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeAnnotationsChangedPredicate filters node updates which don't change annotations the NodeReconciler depends on:
+// the bookkeeping annotations, the ignore and allow-edits annotations, managed annotations, annotations owned by any
+// OwnedLabels, and any annotation if a match expression reads annotations. Other annotations, e.g. of the kubelet or
+// the machine config daemon, change frequently and don't influence the desired state of a node.
+// It is only used for watching nodes in the NodeReconciler, since the Labels and OwnedLabels reconcilers only
+// depend on the ignore annotation, which is checked by nodeChangedPredicate.
+func (r *NodeReconciler) nodeAnnotationsChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, okOld := e.ObjectOld.(*v1.Node)
+			newNode, okNew := e.ObjectNew.(*v1.Node)
+			if !okOld || !okNew {
+				return true
+			}
+			changed := changedAnnotations(oldNode.Annotations, newNode.Annotations)
+			if len(changed) == 0 {
+				return false
+			}
+			relevant := sets.NewString(v1beta1.AnnotationManagedLabels, v1beta1.AnnotationManagedAnnotations,
+				v1beta1.AnnotationIgnore, v1beta1.AnnotationAllowEdits)
+			relevant.Insert(pkg.GetManagedAnnotations(oldNode)...)
+			relevant.Insert(pkg.GetManagedAnnotations(newNode)...)
+			if relevant.HasAny(changed...) {
+				return true
+			}
+			return r.dependsOnAnnotations(newNode, changed)
+		},
+	}
+}
+
+// changedAnnotations returns the names of all annotations which were added, modified or removed
+func changedAnnotations(oldAnnotations, newAnnotations map[string]string) []string {
+	var changed []string
+	for name, value := range newAnnotations {
+		if oldValue, ok := oldAnnotations[name]; !ok || oldValue != value {
+			changed = append(changed, name)
+		}
+	}
+	for name := range oldAnnotations {
+		if _, ok := newAnnotations[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// dependsOnAnnotations checks if any of the given changed annotations of the given node is owned by any OwnedLabels,
+// or if any Labels or OwnedLabels selects nodes with a match expression reading annotations
+func (r *NodeReconciler) dependsOnAnnotations(node *v1.Node, changed []string) bool {
+	log := r.Log.WithValues("node", node.Name)
+
+	allLabels := &v1beta1.LabelsList{}
+	if err := r.Client.List(context.TODO(), allLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list Labels")
+		return true
+	}
+	for _, labels := range allLabels.Items {
+		if pkg.ExpressionUsesAnnotations(&labels, labels.Spec.MatchExpression) {
+			return true
+		}
+	}
+
+	allOwnedLabels := &v1beta1.OwnedLabelsList{}
+	if err := r.Client.List(context.TODO(), allOwnedLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list OwnedLabels")
+		return true
+	}
+	for _, ownedLabels := range allOwnedLabels.Items {
+		if pkg.ExpressionUsesAnnotations(&ownedLabels, ownedLabels.Spec.MatchExpression) {
+			return true
+		}
+		for _, name := range changed {
+			if pkg.IsOwnedAnnotation(name, ownedLabels, log) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodesForLabels maps a Labels to the nodes matching its rules or its applied rules, and to its removable nodes
+// For updates this is called with both the old and the new object, so nodes which don't match anymore are
+// reconciled as well.
+func (r *NodeReconciler) nodesForLabels(obj client.Object) []reconcile.Request {
+	labels, ok := obj.(*v1beta1.Labels)
+	if !ok {
+		return nil
+	}
+	log := r.Log.WithValues("labels", client.ObjectKeyFromObject(labels))
+
+	nodes := &v1.NodeList{}
+	if err := r.Client.List(context.TODO(), nodes, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list Nodes")
+		return nil
+	}
+
+	applied := pkg.AppliedLabels(*labels)
+	removableNodes := sets.NewString(labels.Status.RemovableNodes...)
+	var requests []reconcile.Request
+	for i, node := range nodes.Items {
+		if pkg.MatchesNode(&nodes.Items[i], *labels, log) || (applied != nil && pkg.MatchesNode(&nodes.Items[i], *applied, log)) ||
+			removableNodes.Has(node.Name) {
+			requests = append(requests, nodeRequest(node.Name))
+		}
+	}
+	return requests
+}
+
+// nodesForOwnedLabels maps an OwnedLabels to the nodes having labels, taints or annotations owned by it
+func (r *NodeReconciler) nodesForOwnedLabels(obj client.Object) []reconcile.Request {
+	ownedLabels, ok := obj.(*v1beta1.OwnedLabels)
+	if !ok {
+		return nil
+	}
+	log := r.Log.WithValues("ownedlabels", client.ObjectKeyFromObject(ownedLabels))
+
+	nodes := &v1.NodeList{}
+	if err := r.Client.List(context.TODO(), nodes, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list Nodes")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i, node := range nodes.Items {
+		if pkg.HasOwnedKeys(&nodes.Items[i], *ownedLabels, log) {
+			requests = append(requests, nodeRequest(node.Name))
+		}
+	}
+	return requests
+}
+
+// nodesForLabelMigration maps a LabelMigration to the nodes having its source labels, which are kept while the
+// LabelMigration is copying, or its target label, which is kept while the LabelMigration exists
+func (r *NodeReconciler) nodesForLabelMigration(obj client.Object) []reconcile.Request {
+	migration, ok := obj.(*v1beta1.LabelMigration)
+	if !ok {
+		return nil
+	}
+	log := r.Log.WithValues("labelmigration", client.ObjectKeyFromObject(migration))
+
+	nodes := &v1.NodeList{}
+	if err := r.Client.List(context.TODO(), nodes, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list Nodes")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i, node := range nodes.Items {
+		if pkg.IsMigrationNode(&nodes.Items[i], *migration, log) {
+			requests = append(requests, nodeRequest(node.Name))
+		}
+	}
+	return requests
+}
+
+// countRemovedLabels counts the labels which were removed from the given node per OwnedLabels
+func (r *NodeReconciler) countRemovedLabels(nodeOrig, node *v1.Node, allOwnedLabels []v1beta1.OwnedLabels, log logr.Logger) {
+	for labelDomainName := range nodeOrig.Labels {
+		if _, ok := node.Labels[labelDomainName]; ok {
+			continue
+		}
+		for _, ownedLabels := range pkg.OwnedLabelsForNode(nodeOrig, allOwnedLabels, log) {
+			if pkg.IsOwnedLabel(labelDomainName, ownedLabels, log) {
+				r.RemovedLabels.Add(client.ObjectKeyFromObject(&ownedLabels), 1)
+			}
+		}
+	}
+}
+
+// recordConflicts records a warning event for each of the given label and annotation conflicts of the given node,
+// which wasn't reported yet
+func (r *NodeReconciler) recordConflicts(node *v1.Node, conflicts map[string]string) {
+	keys := make([]string, 0, len(conflicts))
+	for key := range conflicts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range r.conflicts.update(node.Name, keys) {
+		reason := "LabelConflict"
+		if strings.HasPrefix(key, "annotation ") {
+			reason = "AnnotationConflict"
+		}
+		r.recordEvent(node, v1.EventTypeWarning, reason, conflicts[key])
+	}
+}
+
+// recordEvent records an event for the given node, if a recorder is configured
+func (r *NodeReconciler) recordEvent(node *v1.Node, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(node, eventType, reason, message)
+	}
+}
+
+func nodeRequest(nodeName string) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Name: nodeName}}
+}
@@ -18,94 +18,125 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
 	"github.com/go-logr/logr"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/openshift-kni/node-label-operator/api/v1beta1"
 	"github.com/openshift-kni/node-label-operator/pkg"
 )
 
-// OwnedLabelsReconciler reconciles a OwnedLabels object
+// OwnedLabelsReconciler reconciles the status of a OwnedLabels object
 type OwnedLabelsReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// RemovedLabels counts the removed owned labels for the OwnedLabels status
+	RemovedLabels *RemovedLabelsCounter
+	// DryRun previews the label removals of all OwnedLabels
+	DryRun bool
+	// MaxRemovals limits the number of nodes which can lose owned labels at once, over all OwnedLabels
+	MaxRemovals *intstr.IntOrString
 }
 
 // +kubebuilder:rbac:groups=node-labels.openshift.io,resources=ownedlabels,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=node-labels.openshift.io,resources=ownedlabels/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=node-labels.openshift.io,resources=ownedlabels/finalizers,verbs=update
-// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
-
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the OwnedLabels object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile updates the status of OwnedLabels. Uncovered owned labels are removed from nodes by the NodeReconciler.
+// With removal limits, the removals of all OwnedLabels are checked against the limits here, and the nodes which can
+// lose owned labels are published in the status, so the NodeReconciler doesn't need to check them for every node.
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
 func (r *OwnedLabelsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("ownedlabels", req.NamespacedName)
 
-	// get Labels instance
+	// get OwnedLabels instance
 	ownedLabels := &v1beta1.OwnedLabels{}
 	err := r.Get(ctx, req.NamespacedName, ownedLabels)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
-			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			log.Info("OwnedLabels resource not found, ignoring because it must be deleted and we have nothing to do")
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
-		log.Error(err, "Failed to get Labels")
+		log.Error(err, "Failed to get OwnedLabels")
 		return ctrl.Result{}, err
 	}
 
-	// iterate all nodes
-	// we have to
-	// - remove all owned labels of this CR, if they aren't in any label rule
+	if !ownedLabels.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil
+	}
 
 	// we need all Labels
 	allLabels := &v1beta1.LabelsList{}
-	if err = r.Client.List(context.TODO(), allLabels, &client.ListOptions{}); err != nil {
+	if err = r.Client.List(ctx, allLabels, &client.ListOptions{}); err != nil {
 		log.Error(err, "Failed to list Labels")
 		return ctrl.Result{}, err
 	}
 
-	// get nodes
+	// and nodes
 	nodes := &v1.NodeList{}
-	if err = r.Client.List(context.TODO(), nodes, &client.ListOptions{}); err != nil {
+	if err = r.Client.List(ctx, nodes, &client.ListOptions{}); err != nil {
 		log.Error(err, "Failed to list Nodes")
 		return ctrl.Result{}, err
 	}
+	nodes.Items = pkg.WithoutIgnoredNodes(nodes.Items)
 
-	// and start
-	for i, nodeOrig := range nodes.Items {
-
-		log.Info("checking node", "nodeName", nodeOrig.Name)
-
-		node := nodeOrig.DeepCopy()
-		nodeModified := pkg.RemoveOwnedLabels(node, []v1beta1.OwnedLabels{*ownedLabels}, allLabels.Items, log)
+	// and all OwnedLabels for the global removal limit
+	allOwnedLabels := &v1beta1.OwnedLabelsList{}
+	if err = r.Client.List(ctx, allOwnedLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list OwnedLabels")
+		return ctrl.Result{}, err
+	}
+	blocked := pkg.BlockedOwnedLabels(nodes.Items, pkg.LiveOwnedLabels(allOwnedLabels.Items), allLabels.Items, r.DryRun, r.MaxRemovals, log)
+	blockedMessage, isBlocked := blocked[req.NamespacedName]
 
-		// save node
-		if nodeModified {
-			log.Info("patching node")
-			baseToPatch := client.MergeFrom(&nodes.Items[i])
-			if err := r.Client.Patch(context.TODO(), node, baseToPatch); err != nil {
-				log.Error(err, "Failed to patch Node")
-				return ctrl.Result{}, err
-			}
+	// update status
+	statusOrig := ownedLabels.Status.DeepCopy()
+	pendingNodes := r.updateStatus(ownedLabels, allLabels.Items, nodes.Items, isBlocked, blockedMessage, log)
+	recordPreviews(r.Recorder, ownedLabels, statusOrig.Preview, ownedLabels.Status.Preview)
+	if isBlocked && !meta.IsStatusConditionTrue(statusOrig.Conditions, v1beta1.ConditionRemovalBlocked) && r.Recorder != nil {
+		r.Recorder.Event(ownedLabels, v1.EventTypeWarning, v1beta1.ReasonRemovalLimitExceeded, blockedMessage)
+	}
+	if !equality.Semantic.DeepEqual(statusOrig, &ownedLabels.Status) {
+		log.Info("updating status")
+		if err = r.Status().Update(ctx, ownedLabels); err != nil {
+			log.Error(err, "Failed to update status")
+			return ctrl.Result{}, err
 		}
+	}
 
+	// the approval is only valid for a single removal pass
+	if pkg.IsRemovalApproved(ownedLabels) && pendingNodes == 0 && !r.DryRun && !ownedLabels.Spec.DryRun {
+		log.Info("removing approval of removals")
+		ownedLabelsOrig := ownedLabels.DeepCopy()
+		delete(ownedLabels.Annotations, v1beta1.AnnotationApproveRemovals)
+		if err = r.Patch(ctx, ownedLabels, client.MergeFrom(ownedLabelsOrig)); err != nil {
+			log.Error(err, "Failed to remove approval of removals")
+			return ctrl.Result{}, err
+		}
 	}
 
 	return ctrl.Result{}, nil
@@ -115,5 +146,131 @@ func (r *OwnedLabelsReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 func (r *OwnedLabelsReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.OwnedLabels{}).
+		Watches(&source.Kind{Type: &v1.Node{}}, handler.EnqueueRequestsFromMapFunc(r.ownedLabelsForNode), builder.WithPredicates(nodeChangedPredicate())).
+		Watches(&source.Kind{Type: &v1beta1.Labels{}}, handler.EnqueueRequestsFromMapFunc(r.ownedLabelsForLabels)).
+		Watches(&source.Kind{Type: &v1beta1.OwnedLabels{}}, forgetCompiledHandler()).
 		Complete(r)
 }
+
+// ownedLabelsForLabels maps a Labels to all OwnedLabels, since Labels cover owned labels, and the removals of every
+// OwnedLabels count against the global removal limit
+func (r *OwnedLabelsReconciler) ownedLabelsForLabels(obj client.Object) []reconcile.Request {
+	allOwnedLabels := &v1beta1.OwnedLabelsList{}
+	if err := r.Client.List(context.TODO(), allOwnedLabels, &client.ListOptions{}); err != nil {
+		r.Log.Error(err, "Failed to list OwnedLabels")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ownedLabels := range allOwnedLabels.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ownedLabels)})
+	}
+	return requests
+}
+
+// ownedLabelsForNode maps a node to the OwnedLabels owning any of its labels, taints or annotations
+// For updates this is called with both the old and the new object, so OwnedLabels of removed labels are
+// reconciled as well.
+func (r *OwnedLabelsReconciler) ownedLabelsForNode(obj client.Object) []reconcile.Request {
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return nil
+	}
+	log := r.Log.WithValues("node", node.Name)
+
+	allOwnedLabels := &v1beta1.OwnedLabelsList{}
+	if err := r.Client.List(context.TODO(), allOwnedLabels, &client.ListOptions{}); err != nil {
+		log.Error(err, "Failed to list OwnedLabels")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ownedLabels := range allOwnedLabels.Items {
+		if pkg.HasOwnedKeys(node, ownedLabels, log) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ownedLabels)})
+		}
+	}
+	return requests
+}
+
+// updateStatus updates the status of the given OwnedLabels, based on the given nodes,
+// and returns the number of nodes with pending removals
+func (r *OwnedLabelsReconciler) updateStatus(ownedLabels *v1beta1.OwnedLabels, allLabels []v1beta1.Labels, nodes []v1.Node, blocked bool, blockedMessage string, log logr.Logger) int {
+	ownedLabels.Status.ObservedGeneration = ownedLabels.Generation
+
+	var matchedNodes, removableNodes []string
+	var previews []v1beta1.NodeLabelsPreview
+	for i, node := range nodes {
+		if pkg.HasOwnedKeys(&nodes[i], *ownedLabels, log) {
+			matchedNodes = append(matchedNodes, node.Name)
+		}
+		coveringLabels := pkg.CoveringLabels(node.Name, allLabels, r.DryRun, r.MaxRemovals != nil)
+		if preview := pkg.PreviewOwnedLabels(&nodes[i], *ownedLabels, coveringLabels, log); preview != nil {
+			previews = append(previews, *preview)
+			removableNodes = append(removableNodes, node.Name)
+		}
+	}
+	ownedLabels.Status.MatchedNodesCount, ownedLabels.Status.MatchedNodes = matchedNodesStatus(matchedNodes)
+	pendingNodes := len(previews)
+
+	dryRun := r.DryRun || ownedLabels.Spec.DryRun
+	if dryRun {
+		ownedLabels.Status.Preview = previewStatus(previews)
+	} else {
+		ownedLabels.Status.Preview = nil
+	}
+
+	// without limits or with approved removals all nodes are removable anyway
+	limited := ownedLabels.Spec.MaxRemovals != nil || r.MaxRemovals != nil
+	if !dryRun && limited && !blocked && !pkg.IsRemovalApproved(ownedLabels) {
+		sort.Strings(removableNodes)
+		ownedLabels.Status.RemovableNodes = removableNodes
+	} else {
+		ownedLabels.Status.RemovableNodes = nil
+	}
+
+	if removed := r.RemovedLabels.Take(client.ObjectKeyFromObject(ownedLabels)); removed > 0 {
+		now := metav1.Now()
+		ownedLabels.Status.RemovedLabelsCount = removed
+		ownedLabels.Status.LastRemovalTime = &now
+	}
+
+	generation := ownedLabels.Generation
+	if err := pkg.ValidateOwnedLabels(*ownedLabels); err != nil {
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionInvalidPattern, metav1.ConditionTrue, v1beta1.ReasonInvalidPattern, err.Error())
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonInvalidPattern, "OwnedLabels is invalid")
+		return pendingNodes
+	}
+	setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionInvalidPattern, metav1.ConditionFalse, v1beta1.ReasonValid, "")
+
+	expressionErr := pkg.MatchExpressionError(nodes, ownedLabels, ownedLabels.Spec.MatchExpression)
+	switch {
+	case ownedLabels.Spec.MatchExpression == "":
+		meta.RemoveStatusCondition(&ownedLabels.Status.Conditions, v1beta1.ConditionExpressionFailed)
+	case expressionErr != nil:
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionExpressionFailed, metav1.ConditionTrue, v1beta1.ReasonExpressionFailed, expressionErr.Error())
+	default:
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionExpressionFailed, metav1.ConditionFalse, v1beta1.ReasonExpressionEvaluated, "")
+	}
+
+	if blocked {
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionRemovalBlocked, metav1.ConditionTrue, v1beta1.ReasonRemovalLimitExceeded,
+			fmt.Sprintf("%s, set the %s annotation to \"true\" for approving the removals", blockedMessage, v1beta1.AnnotationApproveRemovals))
+	} else {
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionRemovalBlocked, metav1.ConditionFalse, v1beta1.ReasonRemovalAllowed, "")
+	}
+
+	switch {
+	case expressionErr != nil:
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonExpressionFailed, "match expression can't be evaluated for all nodes")
+	case dryRun && pendingNodes > 0:
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonDryRun, fmt.Sprintf("dry-run mode, uncovered owned labels would be removed from %d nodes", pendingNodes))
+	case blocked:
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonRemovalLimitExceeded, "removal of uncovered owned labels is blocked")
+	case pendingNodes > 0:
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionFalse, v1beta1.ReasonProgressing, fmt.Sprintf("uncovered owned labels are not removed yet from %d nodes", pendingNodes))
+	default:
+		setCondition(&ownedLabels.Status.Conditions, generation, v1beta1.ConditionReady, metav1.ConditionTrue, v1beta1.ReasonApplied, "no uncovered owned labels on any node")
+	}
+	return pendingNodes
+}
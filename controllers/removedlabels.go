@@ -0,0 +1,59 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RemovedLabelsCounter counts the labels removed by the NodeReconciler per OwnedLabels,
+// until the OwnedLabelsReconciler reports them in the OwnedLabels status
+type RemovedLabelsCounter struct {
+	lock   sync.Mutex
+	counts map[types.NamespacedName]int32
+}
+
+// NewRemovedLabelsCounter returns a new RemovedLabelsCounter
+func NewRemovedLabelsCounter() *RemovedLabelsCounter {
+	return &RemovedLabelsCounter{
+		counts: make(map[types.NamespacedName]int32),
+	}
+}
+
+// Add adds the given count of removed labels for the given OwnedLabels
+func (c *RemovedLabelsCounter) Add(ownedLabels types.NamespacedName, count int32) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.counts[ownedLabels] += count
+}
+
+// Take returns the count of removed labels for the given OwnedLabels and resets it
+func (c *RemovedLabelsCounter) Take(ownedLabels types.NamespacedName) int32 {
+	if c == nil {
+		return 0
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	count := c.counts[ownedLabels]
+	delete(c.counts, ownedLabels)
+	return count
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+	"github.com/openshift-kni/node-label-operator/pkg"
+)
+
+// maxConflictsInMessage is the maximum number of conflicts listed in a condition message
+const maxConflictsInMessage = 5
+
+// setCondition sets the given condition, the last transition time is only updated if the status changes
+func setCondition(conditions *[]metav1.Condition, generation int64, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// matchedNodesStatus returns the count of the given node names, and the sorted and truncated node names
+func matchedNodesStatus(nodeNames []string) (int32, []string) {
+	sort.Strings(nodeNames)
+	count := int32(len(nodeNames))
+	if len(nodeNames) > v1beta1.MaxStatusNodes {
+		nodeNames = nodeNames[:v1beta1.MaxStatusNodes]
+	}
+	return count, nodeNames
+}
+
+// migrationNodeStatePriority orders node migration states for truncating the status, states which need attention first
+var migrationNodeStatePriority = map[v1beta1.NodeMigrationState]int{
+	v1beta1.NodeMigrationConflict: 0,
+	v1beta1.NodeMigrationPending:  1,
+	v1beta1.NodeMigrationCopied:   2,
+	v1beta1.NodeMigrationCovered:  3,
+	v1beta1.NodeMigrationNotOwned: 4,
+	v1beta1.NodeMigrationRemoved:  5,
+}
+
+// migrationNodesStatus returns the given node migrations truncated and sorted by node name. Nodes which need
+// attention are kept first.
+func migrationNodesStatus(nodeMigrations []v1beta1.NodeMigration) []v1beta1.NodeMigration {
+	result := append([]v1beta1.NodeMigration(nil), nodeMigrations...)
+	if len(result) > v1beta1.MaxStatusNodes {
+		sort.SliceStable(result, func(i, j int) bool {
+			return migrationNodeStatePriority[result[i].State] < migrationNodeStatePriority[result[j].State]
+		})
+		result = result[:v1beta1.MaxStatusNodes]
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NodeName < result[j].NodeName
+	})
+	return result
+}
+
+// conflictsMessage returns a human readable message for the given conflicts
+func conflictsMessage(conflicts []pkg.LabelConflict) string {
+	var messages []string
+	for i, conflict := range conflicts {
+		if i == maxConflictsInMessage {
+			messages = append(messages, fmt.Sprintf("and %d more", len(conflicts)-maxConflictsInMessage))
+			break
+		}
+		messages = append(messages, conflictMessage(conflict))
+	}
+	return strings.Join(messages, ", ")
+}
+
+// conflictMessage returns a human readable message for the given conflict
+func conflictMessage(conflict pkg.LabelConflict) string {
+	return fmt.Sprintf("label %s=%s on node %s is overridden by value %s of Labels %s",
+		conflict.LabelName, conflict.Value, conflict.NodeName, conflict.OtherValue, conflict.OtherLabels)
+}
+
+// previewStatus returns the given previews sorted by node name and truncated
+func previewStatus(previews []v1beta1.NodeLabelsPreview) []v1beta1.NodeLabelsPreview {
+	sort.Slice(previews, func(i, j int) bool {
+		return previews[i].NodeName < previews[j].NodeName
+	})
+	if len(previews) > v1beta1.MaxStatusNodes {
+		previews = previews[:v1beta1.MaxStatusNodes]
+	}
+	return previews
+}
+
+// previewMessage returns a human readable message for the given preview
+func previewMessage(preview v1beta1.NodeLabelsPreview) string {
+	var changes []string
+	if len(preview.WouldAdd) > 0 {
+		changes = append(changes, "would add "+labelsMessage(preview.WouldAdd))
+	}
+	if len(preview.WouldChange) > 0 {
+		changes = append(changes, "would change "+labelsMessage(preview.WouldChange))
+	}
+	if len(preview.WouldRemove) > 0 {
+		changes = append(changes, "would remove "+strings.Join(preview.WouldRemove, " "))
+	}
+	if len(preview.WouldAddAnnotations) > 0 {
+		changes = append(changes, "would add annotations "+labelsMessage(preview.WouldAddAnnotations))
+	}
+	if len(preview.WouldRemoveAnnotations) > 0 {
+		changes = append(changes, "would remove annotations "+strings.Join(preview.WouldRemoveAnnotations, " "))
+	}
+	if len(preview.WouldAddTaints) > 0 {
+		changes = append(changes, "would add taints "+taintsMessage(preview.WouldAddTaints))
+	}
+	if len(preview.WouldRemoveTaints) > 0 {
+		changes = append(changes, "would remove taints "+taintsMessage(preview.WouldRemoveTaints))
+	}
+	return fmt.Sprintf("node %s: %s", preview.NodeName, strings.Join(changes, ", "))
+}
+
+// labelsMessage returns the given labels as sorted name=value pairs
+func labelsMessage(labels map[string]string) string {
+	var pairs []string
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// taintsMessage returns the given taints in the key=value:effect format
+func taintsMessage(taints []v1.Taint) string {
+	var messages []string
+	for _, taint := range taints {
+		messages = append(messages, taint.ToString())
+	}
+	return strings.Join(messages, " ")
+}
+
+// recordPreviews emits an event for each of the given previews, which isn't part of the original previews
+func recordPreviews(recorder record.EventRecorder, obj runtime.Object, previewsOrig, previews []v1beta1.NodeLabelsPreview) {
+	if recorder == nil {
+		return
+	}
+	for _, preview := range previews {
+		known := false
+		for _, previewOrig := range previewsOrig {
+			if equality.Semantic.DeepEqual(preview, previewOrig) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			recorder.Event(obj, v1.EventTypeNormal, v1beta1.ReasonDryRun, previewMessage(preview))
+		}
+	}
+}
@@ -80,14 +80,34 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&LabelsReconciler{
-		Client: k8sManager.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Labels"),
+		Client:   k8sManager.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("Labels"),
+		Recorder: k8sManager.GetEventRecorderFor(FieldManager),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	removedLabels := NewRemovedLabelsCounter()
+
 	err = (&OwnedLabelsReconciler{
-		Client: k8sManager.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("OwnedLabels"),
+		Client:        k8sManager.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("OwnedLabels"),
+		Recorder:      k8sManager.GetEventRecorderFor(FieldManager),
+		RemovedLabels: removedLabels,
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&NodeReconciler{
+		Client:        k8sManager.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("Node"),
+		Recorder:      k8sManager.GetEventRecorderFor(FieldManager),
+		RemovedLabels: removedLabels,
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&LabelMigrationReconciler{
+		Client:   k8sManager.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("LabelMigration"),
+		Recorder: k8sManager.GetEventRecorderFor(FieldManager),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+	. "github.com/openshift-kni/node-label-operator/pkg/test"
+)
+
+// Note: this file hasn't the _test.go postfix because it is reused by e2e tests,
+// and _test.go files are only compiled if their own package is under test.
+
+var _ = Describe("LabelMigration controller", func() {
+
+	const sourceLabel = LabelDomain + "/old-zone"
+	const targetLabel = "example.openshift.io/zone"
+
+	var node *v1.Node
+	var otherNode *v1.Node
+	var migration *v1beta1.LabelMigration
+	var ownedLabels *v1beta1.OwnedLabels
+	var k8sClient client.Client
+
+	BeforeEach(func() {
+
+		k8sClient = *K8sClient // from test package
+
+		nodes := FindWorkerNodes()
+		node = nodes[0]
+		otherNode = nodes[1]
+
+		By("Adding the source label to the node")
+		nodeOrig := node.DeepCopy()
+		node.Labels[sourceLabel] = "east"
+		Expect(k8sClient.Patch(context.Background(), node, client.MergeFrom(nodeOrig))).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		By("Cleaning up nodes, labels and the migration")
+		for _, n := range []*v1.Node{node, otherNode} {
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(n), n)).Should(Succeed())
+			nodeOrig := n.DeepCopy()
+			delete(n.Labels, sourceLabel)
+			delete(n.Labels, targetLabel)
+			Expect(k8sClient.Patch(context.Background(), n, client.MergeFrom(nodeOrig))).Should(Succeed())
+		}
+		CleanupDummyNodes()
+
+		for _, obj := range []client.Object{migration, ownedLabels} {
+			Expect(k8sClient.Delete(context.Background(), obj)).Should(Succeed(), "object should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "object should be away")
+		}
+	})
+
+	When("Creating a LabelMigration", func() {
+
+		It("Should copy the mapped value and remove the owned source label", func() {
+
+			By("Creating a LabelMigration")
+			migration = GetLabelMigration(LabelDomain+"/old-.*", targetLabel)
+			migration.Spec.ValueMapping = map[string]string{"east": "zone-a"}
+			Expect(k8sClient.Create(context.Background(), migration)).Should(Succeed(), "labelMigration should have been created")
+
+			By("Verifying that the target label was set with the mapped value")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(node), node)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", node.Labels)))
+				return node.Labels[targetLabel] == "zone-a"
+			}, Timeout, Interval).Should(BeTrue(), "target label should have been set")
+
+			By("Verifying that the source label isn't removed, because it isn't owned")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(migration), migration)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", migration.Status)))
+				return migration.Status.Phase == v1beta1.MigrationPhaseCompleted &&
+					len(migration.Status.Nodes) > 0 && migration.Status.Nodes[0].State == v1beta1.NodeMigrationNotOwned
+			}, Timeout, Interval).Should(BeTrue(), "migration should have been completed")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(node), node)).Should(Succeed())
+			Expect(node.Labels).To(HaveKeyWithValue(sourceLabel, "east"), "source label should not be removed")
+
+			By("Creating OwnedLabels for the source label")
+			ownedLabels = GetOwnedLabels()
+			ownedLabels.Spec.NamePattern = pointer.StringPtr("old-.*")
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been created")
+
+			By("Verifying that the source label was removed")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(node), node)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", node.Labels)))
+				_, ok := node.Labels[sourceLabel]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "source label should have been removed")
+			Expect(node.Labels).To(HaveKeyWithValue(targetLabel, "zone-a"), "target label should be kept")
+
+			By("Verifying the status")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(migration), migration)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", migration.Status)))
+				return migration.Status.RemovedNodesCount == 1 &&
+					migration.Status.Nodes[0].State == v1beta1.NodeMigrationRemoved &&
+					meta.IsStatusConditionTrue(migration.Status.Conditions, v1beta1.ConditionReady)
+			}, Timeout, Interval).Should(BeTrue(), "status should have been updated")
+
+		})
+
+		It("Should migrate the source labels of all matching nodes", func() {
+
+			By("Adding the source label with another value to the other node")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(otherNode), otherNode)).Should(Succeed())
+			nodeOrig := otherNode.DeepCopy()
+			otherNode.Labels[sourceLabel] = "west"
+			Expect(k8sClient.Patch(context.Background(), otherNode, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+			By("Creating a LabelMigration")
+			migration = GetLabelMigration(LabelDomain+"/old-.*", targetLabel)
+			migration.Spec.ValueMapping = map[string]string{"east": "zone-a", "west": "zone-b"}
+			Expect(k8sClient.Create(context.Background(), migration)).Should(Succeed(), "labelMigration should have been created")
+
+			By("Verifying that the target label was copied to both nodes")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(migration), migration)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", migration.Status)))
+				return migration.Status.CopiedNodesCount == 2
+			}, Timeout, Interval).Should(BeTrue(), "target label should have been copied")
+
+			By("Creating OwnedLabels for the source label")
+			ownedLabels = GetOwnedLabels()
+			ownedLabels.Spec.NamePattern = pointer.StringPtr("old-.*")
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been created")
+
+			By("Verifying that both nodes have the mapped target label, and lost the source label")
+			for n, value := range map[*v1.Node]string{node: "zone-a", otherNode: "zone-b"} {
+				Eventually(func() bool {
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(n), n)).Should(Succeed())
+					GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", n.Labels)))
+					_, hasSource := n.Labels[sourceLabel]
+					return !hasSource && n.Labels[targetLabel] == value
+				}, Timeout, Interval).Should(BeTrue(), "node %s should have been migrated", n.Name)
+			}
+
+			By("Verifying the status")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(migration), migration)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", migration.Status)))
+				return migration.Status.Phase == v1beta1.MigrationPhaseCompleted &&
+					migration.Status.MatchedNodesCount == 2 &&
+					migration.Status.CopiedNodesCount == 2 &&
+					migration.Status.RemovedNodesCount == 2 &&
+					len(migration.Status.Nodes) == 2 &&
+					meta.IsStatusConditionTrue(migration.Status.Conditions, v1beta1.ConditionReady)
+			}, Timeout, Interval).Should(BeTrue(), "status should have been updated")
+
+		})
+
+	})
+
+})
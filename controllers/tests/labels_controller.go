@@ -3,12 +3,16 @@ package tests
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -78,6 +82,1112 @@ var _ = Describe("Labels controller", func() {
 		})
 	})
 
+	When("Labels are applied", func() {
+		It("Should report the matching node in the status", func() {
+
+			By("Verifying the status")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(labels), labels)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", labels.Status)))
+				return labels.Status.ObservedGeneration == labels.Generation &&
+					labels.Status.MatchedNodesCount == 1 &&
+					len(labels.Status.MatchedNodes) == 1 && labels.Status.MatchedNodes[0] == nodeMatching.Name &&
+					meta.IsStatusConditionTrue(labels.Status.Conditions, v1beta1.ConditionReady) &&
+					meta.IsStatusConditionFalse(labels.Status.Conditions, v1beta1.ConditionInvalidPattern) &&
+					meta.IsStatusConditionFalse(labels.Status.Conditions, v1beta1.ConditionConflicting)
+			}, Timeout, Interval).Should(BeTrue(), "status should have been updated")
+
+		})
+	})
+
+	When("Creating a Labels CR with templates", func() {
+
+		var templateLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), templateLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(templateLabels), templateLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should add label with expanded template to matching node", func() {
+
+			By("Creating a Labels CR with a templated label value")
+			nodeNamePattern := GetPattern(nodeMatching.Name, nodeNotMatching.Name)
+			templateLabels = GetLabels(fmt.Sprintf("(?P<name>%s)", nodeNamePattern))
+			templateLabels.Spec.Labels = map[string]string{LabelDomainNameNew: "${name}"}
+			Expect(k8sClient.Create(context.Background(), templateLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that label with expanded value was set on matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == nodeMatching.Name
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+		})
+	})
+
+	When("Creating a Labels CR with a node selector", func() {
+
+		var selectorLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), selectorLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(selectorLabels), selectorLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should add label to nodes matching the selector", func() {
+
+			By("Verifying that label was set on matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				val, ok := nodeMatching.Labels[LabelDomainName]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Creating a Labels CR selecting nodes with the label")
+			selectorLabels = GetLabelsWithSelector(&metav1.LabelSelector{
+				MatchLabels: Label,
+			})
+			Expect(k8sClient.Create(context.Background(), selectorLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that new label was set on selected node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Verifying that new label was not set on not selected node")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeNotMatching.Labels)))
+				_, ok := nodeNotMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should not have been set")
+
+		})
+
+		It("Should add label to nodes whose labels match the match expression", func() {
+
+			By("Creating a Labels CR reading the label of the other Labels in its match expression")
+			selectorLabels = GetLabelsWithSelector(nil)
+			selectorLabels.Spec.MatchExpression = fmt.Sprintf("node.metadata.labels[%q] == %q", LabelDomainName, LabelValue)
+			Expect(k8sClient.Create(context.Background(), selectorLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that new label was set on selected node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Verifying that new label was not set on not selected node")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+				_, ok := nodeNotMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should not have been set")
+
+		})
+
+		It("Should remove derived labels when their source label is removed", func() {
+
+			By("Creating a Labels CR deriving a label from the label of the other Labels")
+			selectorLabels = GetLabelsWithSelector(&metav1.LabelSelector{
+				MatchLabels: Label,
+			})
+			Expect(k8sClient.Create(context.Background(), selectorLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that the derived label was set on the matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "derived label should have been set")
+
+			By("Deleting the Labels CR setting the source label")
+			Expect(k8sClient.Delete(context.Background(), labels)).Should(Succeed(), "labels should have been deleted")
+			labelsDeletedByTest = true
+
+			By("Verifying that source and derived label were removed from the node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				_, hasSource := nodeMatching.Labels[LabelDomainName]
+				_, hasDerived := nodeMatching.Labels[LabelDomainNameNew]
+				return hasSource || hasDerived
+			}, Timeout, Interval).Should(BeFalse(), "source and derived label should have been removed")
+
+		})
+	})
+
+	When("Creating a Labels CR with exclusions", func() {
+
+		var excludingLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), excludingLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(excludingLabels), excludingLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should not add label to excluded nodes", func() {
+
+			By("Creating a Labels CR matching both nodes, excluding one of them")
+			excludingLabels = GetLabels(fmt.Sprintf("(%s|%s)", nodeMatching.Name, nodeNotMatching.Name))
+			excludingLabels.Spec.Labels = LabelNewName
+			excludingLabels.Spec.ExcludeNodeNamePatterns = []string{nodeNotMatching.Name}
+			Expect(k8sClient.Create(context.Background(), excludingLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that label was set on the not excluded node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Verifying that label was not set on the excluded node")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeNotMatching.Labels)))
+				_, ok := nodeNotMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should not have been set")
+
+		})
+
+		It("Should not add label to nodes matching the exclude node selector", func() {
+
+			By("Labeling one node as excluded")
+			excludedLabel := map[string]string{LabelDomain + "/excluded": "true"}
+			patchNodeLabels := func(node *v1.Node, labels map[string]string) {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(node), node)).Should(Succeed())
+				patch := client.MergeFrom(node.DeepCopy())
+				if node.Labels == nil {
+					node.Labels = map[string]string{}
+				}
+				for name, value := range labels {
+					if value == "" {
+						delete(node.Labels, name)
+					} else {
+						node.Labels[name] = value
+					}
+				}
+				Expect(k8sClient.Patch(context.Background(), node, patch)).Should(Succeed(), "node should have been patched")
+			}
+			patchNodeLabels(nodeNotMatching, excludedLabel)
+			defer patchNodeLabels(nodeNotMatching, map[string]string{LabelDomain + "/excluded": ""})
+
+			By("Creating a Labels CR matching both nodes, excluding nodes by label")
+			excludingLabels = GetLabels(fmt.Sprintf("(%s|%s)", nodeMatching.Name, nodeNotMatching.Name))
+			excludingLabels.Spec.Labels = LabelNewName
+			excludingLabels.Spec.ExcludeNodeSelector = &metav1.LabelSelector{MatchLabels: excludedLabel}
+			Expect(k8sClient.Create(context.Background(), excludingLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that label was set on the not excluded node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Verifying that label was not set on the excluded node")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeNotMatching.Labels)))
+				_, ok := nodeNotMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should not have been set")
+
+		})
+
+		It("Should not add label to ignored nodes until the ignore annotation is removed", func() {
+
+			setIgnored := func(node *v1.Node, ignored bool) {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(node), node)).Should(Succeed())
+				patch := client.MergeFrom(node.DeepCopy())
+				if ignored {
+					if node.Annotations == nil {
+						node.Annotations = map[string]string{}
+					}
+					node.Annotations[v1beta1.AnnotationIgnore] = "true"
+				} else {
+					delete(node.Annotations, v1beta1.AnnotationIgnore)
+				}
+				Expect(k8sClient.Patch(context.Background(), node, patch)).Should(Succeed(), "node should have been patched")
+			}
+
+			By("Ignoring one node")
+			setIgnored(nodeNotMatching, true)
+			ignored := true
+			defer func() {
+				if ignored {
+					setIgnored(nodeNotMatching, false)
+				}
+			}()
+
+			By("Creating a Labels CR matching both nodes")
+			excludingLabels = GetLabels(fmt.Sprintf("(%s|%s)", nodeMatching.Name, nodeNotMatching.Name))
+			excludingLabels.Spec.Labels = LabelNewName
+			Expect(k8sClient.Create(context.Background(), excludingLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that only the not ignored node is matched")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(excludingLabels), excludingLabels)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", excludingLabels.Status)))
+				return excludingLabels.Status.ObservedGeneration == excludingLabels.Generation &&
+					excludingLabels.Status.MatchedNodesCount == 1
+			}, Timeout, Interval).Should(BeTrue(), "status should have been updated")
+
+			By("Verifying that label was not set on the ignored node")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeNotMatching.Labels)))
+				_, ok := nodeNotMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should not have been set")
+
+			By("Removing the ignore annotation")
+			setIgnored(nodeNotMatching, false)
+			ignored = false
+
+			By("Verifying that label was set on the node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeNotMatching.Labels)))
+				val, ok := nodeNotMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+		})
+	})
+
+	When("Creating Labels CRs which select nodes by labels of each other", func() {
+
+		var cyclicLabels []*v1beta1.Labels
+
+		AfterEach(func() {
+			for _, labels := range cyclicLabels {
+				Expect(k8sClient.Delete(context.Background(), labels)).Should(Succeed(), "labels should have been deleted")
+			}
+			Eventually(func() bool {
+				for _, labels := range cyclicLabels {
+					err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(labels), labels)
+					if err == nil || !errors.IsNotFound(err) {
+						return false
+					}
+				}
+				return true
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should report the dependency cycle", func() {
+
+			By("Creating two Labels CRs selecting nodes by the label of the other one")
+			first, second := LabelDomain+"/cycle-first", LabelDomain+"/cycle-second"
+			cyclicLabels = []*v1beta1.Labels{
+				GetLabelsWithSelector(&metav1.LabelSelector{MatchLabels: map[string]string{first: LabelValue}}),
+				GetLabelsWithSelector(&metav1.LabelSelector{MatchLabels: map[string]string{second: LabelValue}}),
+			}
+			cyclicLabels[0].Spec.Labels = map[string]string{second: LabelValue}
+			cyclicLabels[1].Spec.Labels = map[string]string{first: LabelValue}
+			for _, labels := range cyclicLabels {
+				Expect(k8sClient.Create(context.Background(), labels)).Should(Succeed(), "labels should have been created")
+			}
+
+			By("Verifying that both Labels report the cycle")
+			Eventually(func() bool {
+				for _, labels := range cyclicLabels {
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(labels), labels)).Should(Succeed())
+					GinkgoWriter.Write([]byte(fmt.Sprintf("conditions: %+v\n", labels.Status.Conditions)))
+					if !meta.IsStatusConditionTrue(labels.Status.Conditions, v1beta1.ConditionDependencyCycle) ||
+						meta.IsStatusConditionTrue(labels.Status.Conditions, v1beta1.ConditionReady) {
+						return false
+					}
+				}
+				return true
+			}, Timeout, Interval).Should(BeTrue(), "dependency cycle should have been reported")
+
+		})
+	})
+
+	When("Creating a Labels CR with an assignment", func() {
+
+		var assignedLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), assignedLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(assignedLabels), assignedLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should add label to the assigned node only", func() {
+
+			By("Creating a Labels CR matching both nodes, assigning one of them")
+			assignedLabels = GetLabels(fmt.Sprintf("(%s|%s)", nodeMatching.Name, nodeNotMatching.Name))
+			assignedLabels.Spec.Labels = LabelNewName
+			maxNodes := int32(1)
+			assignedLabels.Spec.Assignment = &v1beta1.NodeAssignment{MaxNodes: &maxNodes}
+			Expect(k8sClient.Create(context.Background(), assignedLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that one node is assigned")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(assignedLabels), assignedLabels)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", assignedLabels.Status)))
+				return assignedLabels.Status.MatchedNodesCount == 2 && len(assignedLabels.Status.AssignedNodes) == 1
+			}, Timeout, Interval).Should(BeTrue(), "one node should have been assigned")
+
+			assignedNode, otherNode := nodeMatching, nodeNotMatching
+			if assignedLabels.Status.AssignedNodes[0] != assignedNode.Name {
+				assignedNode, otherNode = otherNode, assignedNode
+			}
+
+			By("Verifying that label was set on the assigned node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(assignedNode), assignedNode)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", assignedNode.Labels)))
+				val, ok := assignedNode.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Verifying that label was not set on the other node")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(otherNode), otherNode)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", otherNode.Labels)))
+				_, ok := otherNode.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should not have been set")
+
+		})
+	})
+
+	When("Creating a Labels CR with a node resource requirement", func() {
+
+		var resourceLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			if IsE2etest {
+				return
+			}
+			Expect(k8sClient.Delete(context.Background(), resourceLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(resourceLabels), resourceLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should add label to the nodes with the resource only", func() {
+			if IsE2etest {
+				Skip("node capacity can't be modified in e2e tests")
+			}
+
+			By("Reporting a GPU in the capacity of the matching node")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+			nodeMatching.Status.Capacity = v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}
+			Expect(k8sClient.Status().Update(context.Background(), nodeMatching)).Should(Succeed(), "node status should have been updated")
+
+			By("Creating a Labels CR matching both nodes with a GPU")
+			resourceLabels = GetLabels(fmt.Sprintf("(%s|%s)", nodeMatching.Name, nodeNotMatching.Name))
+			resourceLabels.Spec.Labels = LabelNewName
+			resourceLabels.Spec.NodeResourceRequirements = []v1beta1.NodeResourceRequirement{{
+				Source:   v1beta1.ResourceSourceCapacity,
+				Name:     "nvidia.com/gpu",
+				Operator: v1beta1.ResourceOpGt,
+				Value:    resource.MustParse("0"),
+			}}
+			Expect(k8sClient.Create(context.Background(), resourceLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that label was set on the node with a GPU")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Verifying that label was not set on the node without GPU")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeNotMatching.Labels)))
+				_, ok := nodeNotMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should not have been set")
+
+		})
+	})
+
+	When("Creating a Labels CR with a node condition requirement", func() {
+
+		var conditionLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			if IsE2etest {
+				return
+			}
+			Expect(k8sClient.Delete(context.Background(), conditionLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(conditionLabels), conditionLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should add and remove label following the node condition", func() {
+			if IsE2etest {
+				Skip("node conditions can't be modified in e2e tests")
+			}
+
+			setDiskPressure := func(status v1.ConditionStatus) {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				nodeMatching.Status.Conditions = []v1.NodeCondition{{
+					Type:               v1.NodeDiskPressure,
+					Status:             status,
+					LastTransitionTime: metav1.Now(),
+				}}
+				Expect(k8sClient.Status().Update(context.Background(), nodeMatching)).Should(Succeed(), "node status should have been updated")
+			}
+
+			By("Creating a Labels CR matching nodes with disk pressure")
+			conditionLabels = GetLabels(nodeMatching.Name)
+			conditionLabels.Spec.Labels = LabelNewName
+			conditionLabels.Spec.NodeConditionRequirements = []v1beta1.NodeConditionRequirement{{
+				Type:   v1.NodeDiskPressure,
+				Status: v1.ConditionTrue,
+			}}
+			Expect(k8sClient.Create(context.Background(), conditionLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Reporting disk pressure on the node")
+			setDiskPressure(v1.ConditionTrue)
+
+			By("Verifying that label was set on the node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Clearing disk pressure on the node")
+			setDiskPressure(v1.ConditionFalse)
+
+			By("Verifying that label was removed from the node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				_, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should have been removed")
+
+		})
+
+		It("Should add label only after the condition lasted the minimum duration", func() {
+			if IsE2etest {
+				Skip("node conditions can't be modified in e2e tests")
+			}
+
+			minDuration := 3 * time.Second
+
+			By("Creating a Labels CR matching nodes with disk pressure for a minimum duration")
+			conditionLabels = GetLabels(nodeMatching.Name)
+			conditionLabels.Spec.Labels = LabelNewName
+			conditionLabels.Spec.NodeConditionRequirements = []v1beta1.NodeConditionRequirement{{
+				Type:        v1.NodeDiskPressure,
+				Status:      v1.ConditionTrue,
+				MinDuration: &metav1.Duration{Duration: minDuration},
+			}}
+			Expect(k8sClient.Create(context.Background(), conditionLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Reporting disk pressure on the node")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+			transition := metav1.Now()
+			nodeMatching.Status.Conditions = []v1.NodeCondition{{
+				Type:               v1.NodeDiskPressure,
+				Status:             v1.ConditionTrue,
+				LastTransitionTime: transition,
+			}}
+			Expect(k8sClient.Status().Update(context.Background(), nodeMatching)).Should(Succeed(), "node status should have been updated")
+
+			By("Verifying that label isn't set before the minimum duration elapsed")
+			// the transition time is stored with second precision, so the label can appear up to a second early
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				_, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, minDuration-time.Second-time.Since(transition.Time), Interval).Should(BeFalse(), "label should not have been set yet")
+
+			By("Verifying that label is set after the minimum duration elapsed")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+			Expect(time.Since(transition.Time)).To(BeNumerically(">=", minDuration-time.Second), "label should not have been set before the minimum duration")
+
+		})
+	})
+
+	When("Creating a Labels CR with a node address selector", func() {
+
+		var addressLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			if IsE2etest {
+				return
+			}
+			Expect(k8sClient.Delete(context.Background(), addressLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(addressLabels), addressLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should set the label value of the matching CIDR", func() {
+			if IsE2etest {
+				Skip("node addresses can't be modified in e2e tests")
+			}
+
+			setAddresses := func(node *v1.Node, addresses ...string) {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(node), node)).Should(Succeed())
+				node.Status.Addresses = nil
+				for _, address := range addresses {
+					node.Status.Addresses = append(node.Status.Addresses, v1.NodeAddress{Type: v1.NodeInternalIP, Address: address})
+				}
+				Expect(k8sClient.Status().Update(context.Background(), node)).Should(Succeed(), "node status should have been updated")
+			}
+			expectLabelValue := func(node *v1.Node, value string) {
+				Eventually(func() bool {
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(node), node)).Should(Succeed())
+					GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", node.Labels)))
+					val, ok := node.Labels[LabelDomainNameNew]
+					if value == "" {
+						return !ok
+					}
+					return ok && val == value
+				}, Timeout, Interval).Should(BeTrue(), "label should have value %q", value)
+			}
+
+			By("Reporting dual-stack addresses of both nodes")
+			setAddresses(nodeMatching, "10.0.1.5", "fd00:2::5")
+			setAddresses(nodeNotMatching, "10.0.3.5", "fd00:2::6")
+
+			By("Creating a Labels CR mapping IPv4 and IPv6 CIDRs to label values")
+			addressLabels = GetLabels(fmt.Sprintf("(%s|%s)", nodeMatching.Name, nodeNotMatching.Name))
+			addressLabels.Spec.Labels = nil
+			addressLabels.Spec.NodeAddressSelector = &v1beta1.NodeAddressSelector{
+				Type:      v1.NodeInternalIP,
+				CIDRs:     []string{"10.0.1.0/24", "fd00:2::/64"},
+				LabelName: LabelDomainNameNew,
+				Values:    map[string]string{"10.0.1.0/24": "r1", "fd00:2::/64": "r2"},
+			}
+			Expect(k8sClient.Create(context.Background(), addressLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that the first matching CIDR determines the value")
+			expectLabelValue(nodeMatching, "r1")
+
+			By("Verifying that the IPv6 CIDR matches the node without matching IPv4 address")
+			expectLabelValue(nodeNotMatching, "r2")
+
+			By("Removing the IPv6 address of the second node")
+			setAddresses(nodeNotMatching, "10.0.3.5")
+
+			By("Verifying that the label was removed from the second node")
+			expectLabelValue(nodeNotMatching, "")
+
+		})
+	})
+
+	When("Creating a Labels CR with a match expression", func() {
+
+		var expressionLabels *v1beta1.Labels
+
+		BeforeEach(func() {
+			expressionLabels = nil
+		})
+
+		AfterEach(func() {
+			if expressionLabels == nil {
+				// skipped
+				return
+			}
+			Expect(k8sClient.Delete(context.Background(), expressionLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(expressionLabels), expressionLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should add label to nodes matching the expression", func() {
+
+			By("Creating a Labels CR with an expression matching one node")
+			expressionLabels = GetLabelsWithSelector(nil)
+			expressionLabels.Spec.Labels = LabelNewName
+			expressionLabels.Spec.MatchExpression = fmt.Sprintf("node.metadata.name == %q", nodeMatching.Name)
+			Expect(k8sClient.Create(context.Background(), expressionLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that label was set on the matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Verifying that label was not set on the other node")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeNotMatching.Labels)))
+				_, ok := nodeNotMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should not have been set")
+
+			By("Verifying that the expression was evaluated for all nodes")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(expressionLabels), expressionLabels)).Should(Succeed())
+				return meta.IsStatusConditionFalse(expressionLabels.Status.Conditions, v1beta1.ConditionExpressionFailed)
+			}, Timeout, Interval).Should(BeTrue(), "expression should have been evaluated")
+
+		})
+
+		It("Should add label to nodes which aren't cordoned", func() {
+			if IsE2etest {
+				Skip("nodes aren't cordoned in e2e tests")
+			}
+
+			By("Creating a Labels CR with an expression matching the node if it isn't cordoned")
+			expressionLabels = GetLabels(nodeMatching.Name)
+			expressionLabels.Spec.Labels = LabelNewName
+			expressionLabels.Spec.MatchExpression = "!has(node.spec.unschedulable) || !node.spec.unschedulable"
+			Expect(k8sClient.Create(context.Background(), expressionLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that label was set on the node, which has no unschedulable field")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Verifying that the expression was evaluated without errors")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(expressionLabels), expressionLabels)).Should(Succeed())
+				return meta.IsStatusConditionFalse(expressionLabels.Status.Conditions, v1beta1.ConditionExpressionFailed)
+			}, Timeout, Interval).Should(BeTrue(), "expression should have been evaluated")
+
+			By("Cordoning the node")
+			patch := client.MergeFrom(nodeMatching.DeepCopy())
+			nodeMatching.Spec.Unschedulable = true
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, patch)).Should(Succeed(), "node should have been cordoned")
+
+			By("Verifying that label was removed from the cordoned node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				_, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should have been removed")
+
+		})
+	})
+
+	When("Creating a Labels CR with a time window", func() {
+
+		var scheduledLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), scheduledLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(scheduledLabels), scheduledLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should add label during the time window only", func() {
+
+			// real clusters need more time for reconciling, and the clocks of the test and the operator can be skewed
+			scale := time.Duration(1)
+			if IsE2etest {
+				scale = 10
+			}
+
+			By("Creating a Labels CR which is activated in a few seconds, and expires a few seconds later")
+			scheduledLabels = GetLabels(nodeMatching.Name)
+			scheduledLabels.Spec.Labels = LabelNewName
+			notBefore := metav1.NewTime(time.Now().Add(3 * time.Second * scale))
+			scheduledLabels.Spec.NotBefore = &notBefore
+			scheduledLabels.Spec.TTL = &metav1.Duration{Duration: 4 * time.Second * scale}
+			Expect(k8sClient.Create(context.Background(), scheduledLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that the activation is scheduled")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(scheduledLabels), scheduledLabels)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", scheduledLabels.Status)))
+				active := meta.FindStatusCondition(scheduledLabels.Status.Conditions, v1beta1.ConditionActive)
+				return active != nil && active.Reason == v1beta1.ReasonScheduled && scheduledLabels.Status.NextTransitionTime != nil
+			}, Timeout, Interval).Should(BeTrue(), "activation should have been scheduled")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+			Expect(nodeMatching.Labels).NotTo(HaveKey(LabelDomainNameNew), "label should not have been set yet")
+
+			By("Verifying that label was set after the activation")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValue
+			}, 2*Timeout*scale, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Verifying that label was removed after the expiry")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				_, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, 2*Timeout*scale, Interval).Should(BeFalse(), "label should have been removed")
+
+			By("Verifying that the expiry is reported")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(scheduledLabels), scheduledLabels)).Should(Succeed())
+				active := meta.FindStatusCondition(scheduledLabels.Status.Conditions, v1beta1.ConditionActive)
+				return active != nil && active.Reason == v1beta1.ReasonExpired && scheduledLabels.Status.NextTransitionTime == nil
+			}, Timeout, Interval).Should(BeTrue(), "expiry should have been reported")
+
+		})
+	})
+
+	When("Creating a Labels CR with a distribution", func() {
+
+		var distributedLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), distributedLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(distributedLabels), distributedLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should spread the values over the matching nodes", func() {
+
+			By("Creating a Labels CR distributing two values over both nodes")
+			distributedLabels = GetLabels(fmt.Sprintf("(%s|%s)", nodeMatching.Name, nodeNotMatching.Name))
+			distributedLabels.Spec.Labels = nil
+			distributedLabels.Spec.Distributions = []v1beta1.LabelDistribution{{
+				Name:   LabelDomainNameNew,
+				Values: []string{LabelValue, LabelValueNew},
+			}}
+			Expect(k8sClient.Create(context.Background(), distributedLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that each value was set on one node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeNotMatching), nodeNotMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v %+v\n", nodeMatching.Labels, nodeNotMatching.Labels)))
+				val1, ok1 := nodeMatching.Labels[LabelDomainNameNew]
+				val2, ok2 := nodeNotMatching.Labels[LabelDomainNameNew]
+				return ok1 && ok2 && val1 != val2
+			}, Timeout, Interval).Should(BeTrue(), "values should have been distributed")
+
+			By("Verifying that the distribution is recorded in the status")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(distributedLabels), distributedLabels)).Should(Succeed())
+			Expect(distributedLabels.Status.Distributions).To(HaveLen(1))
+			Expect(distributedLabels.Status.Distributions[0].ValueCounts).To(Equal(map[string]int32{LabelValue: 1, LabelValueNew: 1}))
+
+		})
+	})
+
+	When("Creating a Labels CR with an inventory", func() {
+
+		var inventoryLabels *v1beta1.Labels
+		var inventory *v1.ConfigMap
+
+		AfterEach(func() {
+			if IsE2etest {
+				return
+			}
+			Expect(k8sClient.Delete(context.Background(), inventoryLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(inventoryLabels), inventoryLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+			Expect(k8sClient.Delete(context.Background(), inventory)).Should(Succeed(), "configmap should have been deleted")
+		})
+
+		It("Should add the inventory labels of the matching node", func() {
+			if IsE2etest {
+				Skip("inventories are only read from the operator's namespace in e2e tests")
+			}
+
+			By("Creating an inventory ConfigMap")
+			inventory = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "test-inventory-",
+					Namespace:    "default",
+				},
+				Data: map[string]string{
+					"inventory.csv": fmt.Sprintf("hostname,rack\n%s,%s\n", nodeMatching.Name, LabelValueNew),
+				},
+			}
+			Expect(k8sClient.Create(context.Background(), inventory)).Should(Succeed(), "configmap should have been created")
+
+			By("Creating a Labels CR with the inventory")
+			inventoryLabels = GetLabels(nodeMatching.Name)
+			inventoryLabels.Spec.Labels = nil
+			inventoryLabels.Spec.Inventory = &v1beta1.InventorySource{
+				ConfigMapName: inventory.Name,
+				Key:           "inventory.csv",
+				Format:        "csv",
+				KeyColumn:     "hostname",
+				LabelPrefix:   LabelDomain + "/",
+			}
+			Expect(k8sClient.Create(context.Background(), inventoryLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that the inventory label was set on matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomain+"/rack"]
+				return ok && val == LabelValueNew
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+		})
+	})
+
+	When("Creating a Labels CR with taints", func() {
+
+		var ownedLabels *v1beta1.OwnedLabels
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ownedLabels), ownedLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "ownedlabels should be away")
+		})
+
+		It("Should add and remove owned taints", func() {
+
+			By("Creating OwnedLabels for the taint")
+			ownedLabels = GetOwnedLabels()
+			ownedLabels.Spec.Domain = nil
+			ownedLabels.Spec.TaintKeyPattern = &Taint.Key
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been created")
+
+			By("Adding a taint to the Labels CR")
+			labelsOrig := labels.DeepCopy()
+			labels.Spec.Taints = []v1.Taint{Taint}
+			Expect(k8sClient.Patch(context.Background(), labels, client.MergeFrom(labelsOrig))).Should(Succeed())
+
+			By("Verifying that taint was set on matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("taints: %+v\n", nodeMatching.Spec.Taints)))
+				for _, taint := range nodeMatching.Spec.Taints {
+					if taint.MatchTaint(&Taint) && taint.Value == Taint.Value {
+						return true
+					}
+				}
+				return false
+			}, Timeout, Interval).Should(BeTrue(), "taint should have been set")
+
+			By("Removing the taint from the Labels CR")
+			labelsOrig = labels.DeepCopy()
+			labels.Spec.Taints = nil
+			Expect(k8sClient.Patch(context.Background(), labels, client.MergeFrom(labelsOrig))).Should(Succeed())
+
+			By("Verifying that taint was removed from node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("taints: %+v\n", nodeMatching.Spec.Taints)))
+				for _, taint := range nodeMatching.Spec.Taints {
+					if taint.MatchTaint(&Taint) {
+						return true
+					}
+				}
+				return false
+			}, Timeout, Interval).Should(BeFalse(), "taint should have been removed")
+
+		})
+	})
+
+	When("Adding annotations to a Labels CR", func() {
+		It("Should add and remove managed annotations", func() {
+
+			By("Adding an annotation to the Labels CR")
+			labelsOrig := labels.DeepCopy()
+			labels.Spec.Annotations = Annotation
+			Expect(k8sClient.Patch(context.Background(), labels, client.MergeFrom(labelsOrig))).Should(Succeed())
+
+			By("Verifying that annotation was set on matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("annotations: %+v\n", nodeMatching.Annotations)))
+				val, ok := nodeMatching.Annotations[LabelDomainName]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "annotation should have been set")
+
+			By("Removing the annotation from the Labels CR")
+			labelsOrig = labels.DeepCopy()
+			labels.Spec.Annotations = nil
+			Expect(k8sClient.Patch(context.Background(), labels, client.MergeFrom(labelsOrig))).Should(Succeed())
+
+			By("Verifying that annotation was removed from node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("annotations: %+v\n", nodeMatching.Annotations)))
+				_, ok := nodeMatching.Annotations[LabelDomainName]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "annotation should have been removed")
+
+		})
+	})
+
+	When("Creating a Labels CR in dry-run mode", func() {
+
+		var dryRunLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), dryRunLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(dryRunLabels), dryRunLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should preview the label but not add it", func() {
+
+			By("Creating a Labels CR in dry-run mode")
+			dryRunLabels = GetLabels(GetPattern(nodeMatching.Name, nodeNotMatching.Name))
+			dryRunLabels.Spec.Labels = LabelNewName
+			dryRunLabels.Spec.DryRun = true
+			Expect(k8sClient.Create(context.Background(), dryRunLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that the label is previewed")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(dryRunLabels), dryRunLabels)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", dryRunLabels.Status)))
+				return len(dryRunLabels.Status.Preview) == 1 &&
+					dryRunLabels.Status.Preview[0].NodeName == nodeMatching.Name &&
+					dryRunLabels.Status.Preview[0].WouldAdd[LabelDomainNameNew] == LabelValue &&
+					meta.IsStatusConditionFalse(dryRunLabels.Status.Conditions, v1beta1.ConditionReady)
+			}, Timeout, Interval).Should(BeTrue(), "label should have been previewed")
+
+			By("Verifying that the label was not added")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				_, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should not have been added")
+
+		})
+	})
+
+	When("Enabling dry-run mode on an applied Labels CR", func() {
+		It("Should keep the applied labels and preview their removal", func() {
+
+			By("Verifying that label was set on matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				return nodeMatching.Labels[LabelDomainName] == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(labels), labels)).Should(Succeed())
+				return labels.Status.AppliedSpec != nil
+			}, Timeout, Interval).Should(BeTrue(), "applied spec should have been recorded")
+
+			By("Replacing the label in dry-run mode")
+			labelsOrig := labels.DeepCopy()
+			labels.Spec.DryRun = true
+			labels.Spec.Labels = LabelNewName
+			Expect(k8sClient.Patch(context.Background(), labels, client.MergeFrom(labelsOrig))).Should(Succeed())
+
+			By("Verifying that the removal is previewed")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(labels), labels)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", labels.Status)))
+				return len(labels.Status.Preview) == 1 &&
+					labels.Status.Preview[0].NodeName == nodeMatching.Name &&
+					len(labels.Status.Preview[0].WouldRemove) == 1 && labels.Status.Preview[0].WouldRemove[0] == LabelDomainName &&
+					labels.Status.Preview[0].WouldAdd[LabelDomainNameNew] == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "removal should have been previewed")
+
+			By("Verifying that the applied label was kept")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				_, added := nodeMatching.Labels[LabelDomainNameNew]
+				return nodeMatching.Labels[LabelDomainName] == LabelValue && !added
+			}, Timeout, Interval).Should(BeTrue(), "label should have been kept")
+
+		})
+	})
+
+	When("Creating a conflicting Labels CR", func() {
+
+		var conflictingLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), conflictingLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(conflictingLabels), conflictingLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should apply the value of the Labels with the highest priority", func() {
+
+			By("Verifying that label was set on matching node")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				val, ok := nodeMatching.Labels[LabelDomainName]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+
+			By("Creating a Labels CR with higher priority and another label value")
+			conflictingLabels = GetLabels(GetPattern(nodeMatching.Name, nodeNotMatching.Name))
+			conflictingLabels.Spec.Labels = LabelNewValue
+			conflictingLabels.Spec.Priority = 10
+			Expect(k8sClient.Create(context.Background(), conflictingLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that the value with higher priority was set")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainName]
+				return ok && val == LabelValueNew
+			}, Timeout, Interval).Should(BeTrue(), "label value with higher priority should have been set")
+
+			By("Verifying that only the overridden Labels is conflicting")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(labels), labels)).Should(Succeed())
+				return meta.IsStatusConditionTrue(labels.Status.Conditions, v1beta1.ConditionConflicting)
+			}, Timeout, Interval).Should(BeTrue(), "overridden labels should be conflicting")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(conflictingLabels), conflictingLabels)).Should(Succeed())
+				return meta.IsStatusConditionFalse(conflictingLabels.Status.Conditions, v1beta1.ConditionConflicting)
+			}, Timeout, Interval).Should(BeTrue(), "winning labels should not be conflicting")
+
+		})
+	})
+
 	When("Updating a Labels CR", func() {
 
 		Context("Without OwnedLabels", func() {
@@ -107,7 +1217,7 @@ var _ = Describe("Labels controller", func() {
 
 			})
 
-			It("Should not update label name on matching node, but create a new", func() {
+			It("Should update label name on matching node", func() {
 
 				By("Verifying that label was set on matching node")
 				Eventually(func() bool {
@@ -122,21 +1232,24 @@ var _ = Describe("Labels controller", func() {
 				labels.Spec.Labels = LabelNewName
 				Expect(k8sClient.Patch(context.Background(), labels, client.MergeFrom(labelsOrig))).Should(Succeed())
 
-				By("Verifying that old label exists")
+				By("Verifying that new label exists")
 				Eventually(func() bool {
 					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
 					GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
-					val, ok := nodeMatching.Labels[LabelDomainName]
+					val, ok := nodeMatching.Labels[LabelDomainNameNew]
 					return ok && val == LabelValue
-				}, Timeout, Interval).Should(BeTrue(), "old label should still exist")
+				}, Timeout, Interval).Should(BeTrue(), "new label should have been set")
 
-				By("Verifying that new label exists")
+				By("Verifying that old managed label was deleted")
 				Eventually(func() bool {
 					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
 					GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
-					val, ok := nodeMatching.Labels[LabelDomainNameNew]
-					return ok && val == LabelValue
-				}, Timeout, Interval).Should(BeTrue(), "new label should have been set")
+					_, ok := nodeMatching.Labels[LabelDomainName]
+					return ok
+				}, Timeout, Interval).Should(BeFalse(), "old label should be deleted")
+
+				By("Verifying that only the new label is recorded as managed label")
+				Expect(nodeMatching.Annotations).To(HaveKeyWithValue(v1beta1.AnnotationManagedLabels, LabelDomainNameNew))
 
 			})
 
@@ -200,7 +1313,7 @@ var _ = Describe("Labels controller", func() {
 
 		Context("Without OwnedLabels", func() {
 
-			It("Should not delete label on matching node", func() {
+			It("Should delete managed label on matching node", func() {
 
 				By("Verifying that label was set on matching node")
 				Eventually(func() bool {
@@ -222,13 +1335,90 @@ var _ = Describe("Labels controller", func() {
 				list := &v1beta1.OwnedLabelsList{}
 				Expect(k8sClient.List(context.Background(), list)).To(Succeed())
 				logf.Log.Info(fmt.Sprintf("OwnedLabels %+v", list))
-				By("Verifying labels still exists")
+				By("Verifying label was deleted")
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				Expect(nodeMatching.Labels).ToNot(HaveKey(LabelDomainName), "label should be deleted before the Labels is away")
+				Expect(nodeMatching.Annotations).ToNot(HaveKey(v1beta1.AnnotationManagedLabels), "managed labels should be cleaned up")
+			})
+
+			It("Should keep labels which were already set to the same value by other tools", func() {
+
+				By("Adding a label which isn't managed by the operator")
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				nodeOrig := nodeMatching.DeepCopy()
+				nodeMatching.Labels[LabelDomainNameNew] = LabelValue
+				Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+				By("Creating a Labels CR with the same label")
+				sameLabels := GetLabels(nodeMatching.Name)
+				sameLabels.Spec.Labels = LabelNewName
+				Expect(k8sClient.Create(context.Background(), sameLabels)).Should(Succeed(), "labels should have been created")
+				Eventually(func() bool {
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(sameLabels), sameLabels)).Should(Succeed())
+					GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", sameLabels.Status)))
+					return meta.IsStatusConditionTrue(sameLabels.Status.Conditions, v1beta1.ConditionReady)
+				}, Timeout, Interval).Should(BeTrue(), "labels should be applied")
+
+				By("Deleting the Labels CR")
+				Expect(k8sClient.Delete(context.Background(), sameLabels)).Should(Succeed())
+				Eventually(func() bool {
+					err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(sameLabels), sameLabels)
+					return err != nil && errors.IsNotFound(err)
+				}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+
+				By("Verifying that the label wasn't deleted")
 				Consistently(func() bool {
 					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
 					GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
-					val, ok := nodeMatching.Labels[LabelDomainName]
-					return ok && val == LabelValue
+					return nodeMatching.Labels[LabelDomainNameNew] == LabelValue
 				}, Timeout, Interval).Should(BeTrue(), "label should not be deleted")
+
+				By("Removing the label")
+				nodeOrig = nodeMatching.DeepCopy()
+				delete(nodeMatching.Labels, LabelDomainNameNew)
+				Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+			})
+
+			It("Should keep annotations which were already set to the same value by other tools", func() {
+
+				By("Adding an annotation which isn't managed by the operator")
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				nodeOrig := nodeMatching.DeepCopy()
+				if nodeMatching.Annotations == nil {
+					nodeMatching.Annotations = map[string]string{}
+				}
+				nodeMatching.Annotations[LabelDomainNameNew] = LabelValue
+				Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+				By("Creating a Labels CR with the same annotation")
+				sameLabels := GetLabels(nodeMatching.Name)
+				sameLabels.Spec.Annotations = LabelNewName
+				Expect(k8sClient.Create(context.Background(), sameLabels)).Should(Succeed(), "labels should have been created")
+				Eventually(func() bool {
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(sameLabels), sameLabels)).Should(Succeed())
+					GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", sameLabels.Status)))
+					return meta.IsStatusConditionTrue(sameLabels.Status.Conditions, v1beta1.ConditionReady)
+				}, Timeout, Interval).Should(BeTrue(), "labels should be applied")
+
+				By("Deleting the Labels CR")
+				Expect(k8sClient.Delete(context.Background(), sameLabels)).Should(Succeed())
+				Eventually(func() bool {
+					err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(sameLabels), sameLabels)
+					return err != nil && errors.IsNotFound(err)
+				}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+
+				By("Verifying that the annotation wasn't deleted")
+				Consistently(func() bool {
+					Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+					GinkgoWriter.Write([]byte(fmt.Sprintf("annotations: %+v\n", nodeMatching.Annotations)))
+					return nodeMatching.Annotations[LabelDomainNameNew] == LabelValue
+				}, Timeout, Interval).Should(BeTrue(), "annotation should not be deleted")
+
+				By("Removing the annotation")
+				nodeOrig = nodeMatching.DeepCopy()
+				delete(nodeMatching.Annotations, LabelDomainNameNew)
+				Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
 			})
 
 		})
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+	. "github.com/openshift-kni/node-label-operator/pkg/test"
+)
+
+// Note: this file hasn't the _test.go postfix because it is reused by e2e tests,
+// and _test.go files are only compiled if their own package is under test.
+
+var _ = Describe("Node controller", func() {
+
+	var nodeMatching *v1.Node
+	var labels *v1beta1.Labels
+	var k8sClient client.Client
+
+	BeforeEach(func() {
+
+		k8sClient = *K8sClient // from test package
+
+		nodes := FindWorkerNodes()
+		nodeMatching = nodes[0]
+
+		By("Creating a Labels CR")
+		nodeNamePattern := GetPattern(nodeMatching.Name, nodes[1].Name)
+		labels = GetLabels(nodeNamePattern)
+		Expect(k8sClient.Create(context.Background(), labels)).Should(Succeed(), "labels should have been created")
+
+		By("Verifying that label was set on matching node")
+		Eventually(func() bool {
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+			GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+			val, ok := nodeMatching.Labels[LabelDomainName]
+			return ok && val == LabelValue
+		}, Timeout, Interval).Should(BeTrue(), "label should have been set")
+	})
+
+	AfterEach(func() {
+		By("Cleaning up nodes and labels")
+		CleanupDummyNodes()
+
+		Expect(k8sClient.Delete(context.Background(), labels)).Should(Succeed(), "labels should have been deleted")
+		Eventually(func() bool {
+			err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(labels), labels)
+			return err != nil && errors.IsNotFound(err)
+		}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+	})
+
+	When("A managed label is removed from a node", func() {
+		It("Should restore the label", func() {
+
+			By("Removing the label")
+			nodeOrig := nodeMatching.DeepCopy()
+			delete(nodeMatching.Labels, LabelDomainName)
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+			By("Verifying that label was restored")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainName]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label should have been restored")
+
+		})
+	})
+
+	When("A managed label value is modified on a node", func() {
+		It("Should restore the label value", func() {
+
+			By("Modifying the label value")
+			nodeOrig := nodeMatching.DeepCopy()
+			nodeMatching.Labels[LabelDomainName] = LabelValueNew
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+			By("Verifying that label value was restored")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainName]
+				return ok && val == LabelValue
+			}, Timeout, Interval).Should(BeTrue(), "label value should have been restored")
+
+		})
+	})
+
+	When("A label was already set to another value by another tool", func() {
+
+		var foreignLabels *v1beta1.Labels
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), foreignLabels)).Should(Succeed(), "labels should have been deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(foreignLabels), foreignLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+		})
+
+		It("Should not override the label value", func() {
+
+			By("Setting the label by another tool")
+			nodeOrig := nodeMatching.DeepCopy()
+			nodeMatching.Labels[LabelDomainNameNew] = LabelValueNew
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+			By("Creating a Labels CR for the same label")
+			foreignLabels = GetLabels(nodeMatching.Name)
+			foreignLabels.Spec.Labels = LabelNewName
+			Expect(k8sClient.Create(context.Background(), foreignLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Verifying that label value was not overridden")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok && val == LabelValueNew
+			}, Timeout, Interval).Should(BeTrue(), "label value should not have been overridden")
+
+		})
+	})
+
+})
@@ -9,6 +9,8 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift-kni/node-label-operator/api/v1beta1"
@@ -22,13 +24,11 @@ var _ = Describe("OwnedLabels controller", func() {
 
 	var nodeMatching *v1.Node
 	var labels *v1beta1.Labels
-	var labelsDeletedByTest bool
 	var k8sClient client.Client
 
 	BeforeEach(func() {
 
 		k8sClient = *K8sClient // from test package
-		labelsDeletedByTest = false
 
 		nodes := FindWorkerNodes()
 		nodeMatching = nodes[0]
@@ -43,14 +43,12 @@ var _ = Describe("OwnedLabels controller", func() {
 		By("Cleaning up nodes and labels")
 		CleanupDummyNodes()
 
-		if !labelsDeletedByTest {
-			Expect(k8sClient.Delete(context.Background(), labels)).Should(Succeed(), "labels should have been deleted")
-			By("Ensure Labels is deleted")
-			Eventually(func() bool {
-				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(labels), labels)
-				return err != nil && errors.IsNotFound(err)
-			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
-		}
+		Expect(k8sClient.Delete(context.Background(), labels)).Should(Succeed(), "labels should have been deleted")
+		By("Ensure Labels is deleted")
+		Eventually(func() bool {
+			err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(labels), labels)
+			return err != nil && errors.IsNotFound(err)
+		}, Timeout, Interval).Should(BeTrue(), "labels should be away")
 	})
 
 	When("Creating OwnedLabels", func() {
@@ -65,7 +63,7 @@ var _ = Describe("OwnedLabels controller", func() {
 			}, Timeout, Interval).Should(BeTrue(), "ownedlabels should be away")
 		})
 
-		It("Should delete uncovered labels", func() {
+		It("Should delete uncovered labels written by other tools", func() {
 
 			By("Verifying that label was set on matching node")
 			Eventually(func() bool {
@@ -75,15 +73,16 @@ var _ = Describe("OwnedLabels controller", func() {
 				return ok && val == LabelValue
 			}, Timeout, Interval).Should(BeTrue(), "label should have been set")
 
-			By("Deleting Labels")
-			Expect(k8sClient.Delete(context.Background(), labels)).Should(Succeed())
-			labelsDeletedByTest = true
+			By("Adding a label which isn't managed by the operator")
+			nodeOrig := nodeMatching.DeepCopy()
+			nodeMatching.Labels[LabelDomainNameNew] = LabelValue
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
 
-			By("Verifying that label isn't deleted yet")
+			By("Verifying that unmanaged label isn't deleted yet")
 			Consistently(func() bool {
 				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
 				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
-				val, ok := nodeMatching.Labels[LabelDomainName]
+				val, ok := nodeMatching.Labels[LabelDomainNameNew]
 				return ok && val == LabelValue
 			}, Timeout, Interval).Should(BeTrue(), "label should not be deleted yet")
 
@@ -91,14 +90,185 @@ var _ = Describe("OwnedLabels controller", func() {
 			ownedLabels = GetOwnedLabels()
 			Expect(k8sClient.Create(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been created")
 
+			By("Verifying that unmanaged label is deleted now")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				_, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should be deleted now")
+
+			By("Verifying that covered label still exists")
+			val, ok := nodeMatching.Labels[LabelDomainName]
+			Expect(ok && val == LabelValue).To(BeTrue(), "covered label should not be deleted")
+
+			By("Verifying the status")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ownedLabels), ownedLabels)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", ownedLabels.Status)))
+				return ownedLabels.Status.RemovedLabelsCount >= 1 &&
+					ownedLabels.Status.LastRemovalTime != nil &&
+					meta.IsStatusConditionTrue(ownedLabels.Status.Conditions, v1beta1.ConditionReady)
+			}, Timeout, Interval).Should(BeTrue(), "status should have been updated")
+
+		})
+
+		It("Should block removals exceeding the removal limit until they are approved", func() {
+
+			By("Adding a label which isn't managed by the operator")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+			nodeOrig := nodeMatching.DeepCopy()
+			nodeMatching.Labels[LabelDomainNameNew] = LabelValue
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+			By("Creating OwnedLabels with a removal limit of 0 nodes")
+			ownedLabels = GetOwnedLabels()
+			maxRemovals := intstr.FromInt(0)
+			ownedLabels.Spec.MaxRemovals = &maxRemovals
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been created")
+
+			By("Verifying that removal is blocked")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ownedLabels), ownedLabels)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", ownedLabels.Status)))
+				return meta.IsStatusConditionTrue(ownedLabels.Status.Conditions, v1beta1.ConditionRemovalBlocked)
+			}, Timeout, Interval).Should(BeTrue(), "removal should be blocked")
+			Consistently(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				_, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeTrue(), "label should not be deleted")
+
+			By("Approving the removals")
+			ownedLabelsOrig := ownedLabels.DeepCopy()
+			ownedLabels.Annotations = map[string]string{v1beta1.AnnotationApproveRemovals: "true"}
+			Expect(k8sClient.Patch(context.Background(), ownedLabels, client.MergeFrom(ownedLabelsOrig))).Should(Succeed())
+
 			By("Verifying that label is deleted now")
 			Eventually(func() bool {
 				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
 				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
-				_, ok := nodeMatching.Labels[LabelDomainName]
+				_, ok := nodeMatching.Labels[LabelDomainNameNew]
 				return ok
 			}, Timeout, Interval).Should(BeFalse(), "label should be deleted now")
 
+			By("Verifying that the approval was removed")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ownedLabels), ownedLabels)).Should(Succeed())
+				_, approved := ownedLabels.Annotations[v1beta1.AnnotationApproveRemovals]
+				return approved
+			}, Timeout, Interval).Should(BeFalse(), "approval should have been removed")
+
+		})
+
+		It("Should remove owned labels from removable nodes within the removal limit", func() {
+
+			By("Adding a label which isn't managed by the operator")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+			nodeOrig := nodeMatching.DeepCopy()
+			nodeMatching.Labels[LabelDomainNameNew] = LabelValue
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+			By("Creating OwnedLabels with a removal limit of 100% of the nodes")
+			ownedLabels = GetOwnedLabels()
+			maxRemovals := intstr.FromString("100%")
+			ownedLabels.Spec.MaxRemovals = &maxRemovals
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been created")
+
+			By("Verifying that label is deleted")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				_, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should be deleted")
+
+			By("Verifying the status")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ownedLabels), ownedLabels)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("status: %+v\n", ownedLabels.Status)))
+				return len(ownedLabels.Status.RemovableNodes) == 0 &&
+					meta.IsStatusConditionFalse(ownedLabels.Status.Conditions, v1beta1.ConditionRemovalBlocked) &&
+					meta.IsStatusConditionTrue(ownedLabels.Status.Conditions, v1beta1.ConditionReady)
+			}, Timeout, Interval).Should(BeTrue(), "status should have been updated")
+
+		})
+
+		It("Should delete Labels whose uncovered owned labels are blocked by the removal limit", func() {
+
+			By("Adding a label which isn't managed by the operator")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+			nodeOrig := nodeMatching.DeepCopy()
+			nodeMatching.Labels[LabelDomainNameNew] = LabelValue
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+			By("Creating a Labels CR covering the label")
+			coveringLabels := GetLabels(GetPattern(nodeMatching.Name, ""))
+			coveringLabels.Spec.Labels = LabelNewName
+			Expect(k8sClient.Create(context.Background(), coveringLabels)).Should(Succeed(), "labels should have been created")
+
+			By("Creating OwnedLabels with a removal limit of 0 nodes")
+			ownedLabels = GetOwnedLabels()
+			maxRemovals := intstr.FromInt(0)
+			ownedLabels.Spec.MaxRemovals = &maxRemovals
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been created")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ownedLabels), ownedLabels)).Should(Succeed())
+				return meta.IsStatusConditionTrue(ownedLabels.Status.Conditions, v1beta1.ConditionReady)
+			}, Timeout, Interval).Should(BeTrue(), "ownedLabels should be ready")
+
+			By("Deleting the Labels CR")
+			Expect(k8sClient.Delete(context.Background(), coveringLabels)).Should(Succeed(), "labels should have been deleted")
+
+			By("Verifying that the Labels CR doesn't wait for the blocked removal")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(coveringLabels), coveringLabels)
+				return err != nil && errors.IsNotFound(err)
+			}, Timeout, Interval).Should(BeTrue(), "labels should be away")
+
+			By("Verifying that the label wasn't removed")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ownedLabels), ownedLabels)).Should(Succeed())
+				return meta.IsStatusConditionTrue(ownedLabels.Status.Conditions, v1beta1.ConditionRemovalBlocked)
+			}, Timeout, Interval).Should(BeTrue(), "removal should be blocked")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+			Expect(nodeMatching.Labels).To(HaveKeyWithValue(LabelDomainNameNew, LabelValue), "label should not be deleted")
+
+			By("Removing the label")
+			nodeOrig = nodeMatching.DeepCopy()
+			delete(nodeMatching.Labels, LabelDomainNameNew)
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+		})
+
+		It("Should delete owned labels of Labels whose node name pattern only matches a part of the node name", func() {
+
+			By("Creating a Labels CR with a node name pattern matching a substring of the node name")
+			substringLabels := GetLabels(nodeMatching.Name[1:])
+			substringLabels.Spec.Labels = LabelNewName
+			Expect(k8sClient.Create(context.Background(), substringLabels)).Should(Succeed(), "labels should have been created")
+			defer func() {
+				Expect(k8sClient.Delete(context.Background(), substringLabels)).Should(Succeed(), "labels should have been deleted")
+			}()
+
+			By("Adding the label of the Labels CR to the node")
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+			nodeOrig := nodeMatching.DeepCopy()
+			nodeMatching.Labels[LabelDomainNameNew] = LabelValue
+			Expect(k8sClient.Patch(context.Background(), nodeMatching, client.MergeFrom(nodeOrig))).Should(Succeed())
+
+			By("Creating OwnedLabels")
+			ownedLabels = GetOwnedLabels()
+			Expect(k8sClient.Create(context.Background(), ownedLabels)).Should(Succeed(), "ownedLabels should have been created")
+
+			By("Verifying that the label isn't covered, because node name patterns are anchored")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nodeMatching), nodeMatching)).Should(Succeed())
+				GinkgoWriter.Write([]byte(fmt.Sprintf("labels: %+v\n", nodeMatching.Labels)))
+				_, ok := nodeMatching.Labels[LabelDomainNameNew]
+				return ok
+			}, Timeout, Interval).Should(BeFalse(), "label should be deleted")
+
 		})
 
 	})
@@ -22,12 +22,14 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -58,11 +60,38 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var forceOwnership bool
+	var dryRun bool
+	var maxRemovals string
+	var denyManagedEdits bool
+	var protectLabels bool
+	var protectLabelsAllowedGroups string
+	var inventoryNamespaces string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&forceOwnership, "force-ownership", false,
+		"Take over labels which were already set to another value by other tools. "+
+			"Without this, such labels aren't modified and a conflict event is recorded on the node.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Don't modify any node labels. Instead the changes which would be applied are reported "+
+			"in the status of Labels and OwnedLabels and as events.")
+	flag.StringVar(&maxRemovals, "max-removals", "",
+		"The maximum number or percentage of nodes which can lose owned labels at once, over all OwnedLabels, "+
+			"and managed labels at once, per Labels. Unlimited by default.")
+	flag.BoolVar(&denyManagedEdits, "deny-managed-edits", false,
+		"Deny node updates by other users which remove or modify managed labels or annotations. "+
+			"Without this, such modifications are reverted by the node webhook.")
+	flag.BoolVar(&protectLabels, "protect-labels", false,
+		"Deny node updates by users other than nodes which contradict the labels of a Labels CR, "+
+			"naming the Labels CR in the denial message.")
+	flag.StringVar(&protectLabelsAllowedGroups, "protect-labels-allowed-groups", "",
+		"Comma separated list of groups whose members are allowed to modify protected labels.")
+	flag.StringVar(&inventoryNamespaces, "inventory-namespaces", "",
+		"Comma separated list of namespaces whose ConfigMaps can be used as inventory. "+
+			"Defaults to the operator's namespace, or all namespaces if it is unknown.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -73,6 +102,16 @@ func main() {
 
 	printVersion()
 
+	var globalMaxRemovals *intstr.IntOrString
+	if maxRemovals != "" {
+		value := intstr.Parse(maxRemovals)
+		if err := pkg.ValidateMaxRemovals(&value); err != nil {
+			setupLog.Error(err, "invalid max-removals flag")
+			os.Exit(1)
+		}
+		globalMaxRemovals = &value
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -86,27 +125,60 @@ func main() {
 		os.Exit(1)
 	}
 
+	removedLabels := controllers.NewRemovedLabelsCounter()
 	if err = (&controllers.OwnedLabelsReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("OwnedLabels"),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("OwnedLabels"),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor(controllers.FieldManager),
+		RemovedLabels: removedLabels,
+		DryRun:        dryRun,
+		MaxRemovals:   globalMaxRemovals,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OwnedLabels")
 		os.Exit(1)
 	}
 	if err = (&controllers.LabelsReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Labels"),
-		Scheme: mgr.GetScheme(),
+		Client:              mgr.GetClient(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("Labels"),
+		Scheme:              mgr.GetScheme(),
+		Recorder:            mgr.GetEventRecorderFor(controllers.FieldManager),
+		DryRun:              dryRun,
+		MaxRemovals:         globalMaxRemovals,
+		InventoryNamespaces: getInventoryNamespaces(inventoryNamespaces),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Labels")
 		os.Exit(1)
 	}
+	if err = (&controllers.NodeReconciler{
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("Node"),
+		Scheme:         mgr.GetScheme(),
+		Recorder:       mgr.GetEventRecorderFor(controllers.FieldManager),
+		RemovedLabels:  removedLabels,
+		ForceOwnership: forceOwnership,
+		DryRun:         dryRun,
+		MaxRemovals:    globalMaxRemovals,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Node")
+		os.Exit(1)
+	}
+	if err = (&controllers.LabelMigrationReconciler{
+		Client:      mgr.GetClient(),
+		Log:         ctrl.Log.WithName("controllers").WithName("LabelMigration"),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor(controllers.FieldManager),
+		DryRun:      dryRun,
+		MaxRemovals: globalMaxRemovals,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LabelMigration")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
-	// setup webhooks for not owned resources
-	if err := setupExternalResourcesWebhooks(mgr); err != nil {
-		setupLog.Error(err, "unable to setup external webhooks")
+	// setup webhooks
+	if err := setupWebhooks(mgr, dryRun, forceOwnership, denyManagedEdits, protectLabels, protectLabelsAllowedGroups); err != nil {
+		setupLog.Error(err, "unable to setup webhooks")
 		os.Exit(1)
 	}
 
@@ -132,7 +204,7 @@ const (
 	WebhookKeyName  = "apiserver.key"
 )
 
-func setupExternalResourcesWebhooks(mgr manager.Manager) error {
+func setupWebhooks(mgr manager.Manager, dryRun, forceOwnership, denyManagedEdits, protectLabels bool, protectLabelsAllowedGroups string) error {
 
 	// Make sure the certificates are mounted, this should be handled by the OLM
 	certs := []string{filepath.Join(WebhookCertDir, WebhookCertName), filepath.Join(WebhookCertDir, WebhookKeyName)}
@@ -149,12 +221,63 @@ func setupExternalResourcesWebhooks(mgr manager.Manager) error {
 	server.KeyName = WebhookKeyName
 
 	// setup node webhook
-	(&api.NodeLabeler{}).SetupWebhookWithManager(mgr)
+	username, err := operatorUsername()
+	if err != nil {
+		setupLog.Error(err, "Failed to identify the operator's node updates")
+		return err
+	}
+	(&api.NodeLabeler{
+		DryRun:           dryRun,
+		ForceOwnership:   forceOwnership,
+		OperatorUsername: username,
+		DenyManagedEdits: denyManagedEdits,
+		ProtectLabels:    protectLabels,
+	}).SetupWebhookWithManager(mgr)
+
+	// setup protected labels webhook
+	var allowedGroups []string
+	if protectLabelsAllowedGroups != "" {
+		allowedGroups = strings.Split(protectLabelsAllowedGroups, ",")
+	}
+	(&api.NodeValidator{
+		ProtectLabels:    protectLabels,
+		OperatorUsername: username,
+		AllowedGroups:    allowedGroups,
+	}).SetupWebhookWithManager(mgr)
+
+	// setup validation webhooks
+	(&api.LabelsValidator{}).SetupWebhookWithManager(mgr)
+	(&api.OwnedLabelsValidator{}).SetupWebhookWithManager(mgr)
+	(&api.LabelMigrationValidator{}).SetupWebhookWithManager(mgr)
 
 	return nil
 
 }
 
+// getInventoryNamespaces returns the namespaces of the given comma separated list, or the operator's namespace if
+// the list is empty. No namespaces, meaning all namespaces, are returned if the operator's namespace is unknown.
+func getInventoryNamespaces(namespaces string) []string {
+	if namespaces != "" {
+		return strings.Split(namespaces, ",")
+	}
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return []string{namespace}
+	}
+	return nil
+}
+
+// operatorUsername returns the username of the operator's service account, based on the POD_NAMESPACE and
+// SERVICE_ACCOUNT_NAME environment variables, or an error if they aren't set. Without it the node webhook would
+// restore managed labels which are removed by the operator.
+func operatorUsername() (string, error) {
+	namespace := os.Getenv("POD_NAMESPACE")
+	serviceAccount := os.Getenv("SERVICE_ACCOUNT_NAME")
+	if namespace == "" || serviceAccount == "" {
+		return "", fmt.Errorf("POD_NAMESPACE or SERVICE_ACCOUNT_NAME not set")
+	}
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount), nil
+}
+
 func printVersion() {
 	setupLog.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	setupLog.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
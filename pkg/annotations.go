@@ -0,0 +1,180 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// GetManagedAnnotations returns the names of the annotations which were applied to the given node by the operator
+func GetManagedAnnotations(node *v1.Node) []string {
+	return getManagedKeys(node, v1beta1.AnnotationManagedAnnotations)
+}
+
+// SetManagedAnnotations records the given annotation names as applied by the operator on the given node,
+// and returns true if the node was modified
+func SetManagedAnnotations(node *v1.Node, annotationNames []string) bool {
+	return setManagedKeys(node, v1beta1.AnnotationManagedAnnotations, annotationNames)
+}
+
+// DesiredAnnotations returns the annotations of all given Labels matching the given node.
+// If multiple Labels set the same annotation to different values, the value of the Labels with the highest precedence
+// wins.
+func DesiredAnnotations(node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) map[string]string {
+	desiredAnnotations := map[string]string{}
+	for _, labels := range SortByPrecedence(allLabels) {
+		if !labels.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		for name, value := range AnnotationsForNode(node, labels, log) {
+			if _, exists := desiredAnnotations[name]; !exists {
+				desiredAnnotations[name] = value
+			}
+		}
+	}
+	return desiredAnnotations
+}
+
+// AddAllAnnotations adds the annotations configured in the rules of the given Labels to the given node,
+// and records them as managed annotations.
+// If multiple Labels set the same annotation to different values, the Labels with the highest precedence wins.
+func AddAllAnnotations(node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	return ApplyAnnotations(node, DesiredAnnotations(node, allLabels, log), log)
+}
+
+// ApplyAnnotations adds the given desired annotations to the given node, and records them as managed annotations.
+// Annotations which were already set to the desired value by other tools aren't recorded.
+func ApplyAnnotations(node *v1.Node, desiredAnnotations map[string]string, log logr.Logger) bool {
+	// keep managed annotations which are still on the node, they are removed by RemoveManagedAnnotations only
+	managedAnnotations := make([]string, 0, len(desiredAnnotations))
+	alreadyManaged := map[string]bool{}
+	for _, name := range GetManagedAnnotations(node) {
+		alreadyManaged[name] = true
+		if _, desired := desiredAnnotations[name]; !desired {
+			managedAnnotations = append(managedAnnotations, name)
+		}
+	}
+
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	nodeModified := false
+	for name, value := range desiredAnnotations {
+		// only record annotations we add or modify, so annotations of other tools aren't removed when they aren't
+		// desired anymore
+		val, ok := node.Annotations[name]
+		if alreadyManaged[name] || !ok || val != value {
+			managedAnnotations = append(managedAnnotations, name)
+		}
+		if !ok || val != value {
+			log.Info("Adding annotation to node", "node", node.Name, "annotationName", name, "annotationValue", value)
+			node.Annotations[name] = value
+			nodeModified = true
+		}
+	}
+	return SetManagedAnnotations(node, managedAnnotations) || nodeModified
+}
+
+// ForeignAnnotations returns the sorted names of the given desired annotations, which are already set to another value
+// on the given node, but aren't managed annotations. These annotations were set by other tools.
+func ForeignAnnotations(node *v1.Node, desiredAnnotations map[string]string) []string {
+	managedAnnotations := map[string]bool{}
+	for _, name := range GetManagedAnnotations(node) {
+		managedAnnotations[name] = true
+	}
+	var foreignAnnotations []string
+	for name, value := range desiredAnnotations {
+		if val, ok := node.Annotations[name]; ok && val != value && !managedAnnotations[name] {
+			foreignAnnotations = append(foreignAnnotations, name)
+		}
+	}
+	sort.Strings(foreignAnnotations)
+	return foreignAnnotations
+}
+
+// RemoveManagedAnnotations removes all managed annotations from the node, which aren't covered by any of the given
+// Labels anymore, and returns true if the node was modified
+func RemoveManagedAnnotations(node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	log.Info("Checking managed annotations", "node", node.Name)
+	nodeModified := false
+	var stillManaged []string
+	for _, name := range GetManagedAnnotations(node) {
+		if IsAnnotationCoveredByAll(node, name, allLabels, log) {
+			stillManaged = append(stillManaged, name)
+			continue
+		}
+		if _, ok := node.Annotations[name]; ok {
+			log.Info("Deleting uncovered managed annotation", "node", node.Name, "annotationName", name)
+			delete(node.Annotations, name)
+		}
+		nodeModified = true
+	}
+	if nodeModified {
+		SetManagedAnnotations(node, stillManaged)
+	}
+	return nodeModified
+}
+
+// RemoveOwnedAnnotations removes all uncovered owned annotations from the node and return true if the node was modified
+func RemoveOwnedAnnotations(node *v1.Node, allOwnedLabels []v1beta1.OwnedLabels, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	log.Info("Checking owned annotations", "node", node.Name)
+	nodeModified := false
+	allOwnedLabels = OwnedLabelsForNode(node, allOwnedLabels, log)
+	for name := range node.Annotations {
+		for _, ownedLabels := range allOwnedLabels {
+			if !IsOwnedAnnotation(name, ownedLabels, log) {
+				continue
+			}
+			if !IsAnnotationCoveredByAll(node, name, allLabels, log) {
+				log.Info("Deleting uncovered owned annotation", "node", node.Name, "annotationName", name)
+				delete(node.Annotations, name)
+				nodeModified = true
+			}
+			break
+		}
+	}
+	return nodeModified
+}
+
+// IsAnnotationCoveredByAll checks if the given annotation name is covered by the rules of the given allLabels
+// for the given node
+func IsAnnotationCoveredByAll(node *v1.Node, name string, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	for _, labels := range allLabels {
+		if !labels.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if _, ok := AnnotationsForNode(node, labels, log)[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOwnedAnnotation checks if the given annotation name matches the annotation key pattern of the given OwnedLabels
+func IsOwnedAnnotation(name string, ownedLabels v1beta1.OwnedLabels, log logr.Logger) bool {
+	if ownedLabels.Spec.AnnotationKeyPattern == nil {
+		return false
+	}
+	if isReservedAnnotation(name) {
+		return false
+	}
+	pattern := fmt.Sprintf("%s%s%s", "^", *ownedLabels.Spec.AnnotationKeyPattern, "$")
+	match, err := regexp.MatchString(pattern, name)
+	if err != nil {
+		log.Error(err, "Invalid regular expression, moving on", "pattern", ownedLabels.Spec.AnnotationKeyPattern)
+		return false
+	}
+	return match
+}
+
+// isReservedAnnotation checks if the given annotation name is used by the operator itself
+func isReservedAnnotation(name string) bool {
+	return name == v1beta1.AnnotationManagedLabels || name == v1beta1.AnnotationManagedAnnotations ||
+		name == v1beta1.AnnotationAllowEdits || name == v1beta1.AnnotationIgnore
+}
@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"hash/fnv"
+	"sort"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// IsAssigned checks if the given node is assigned to the given labels.
+// Without assignment all matching nodes are assigned.
+func IsAssigned(node *v1.Node, labels v1beta1.Labels) bool {
+	if labels.Spec.Assignment == nil {
+		return true
+	}
+	return contains(labels.Status.AssignedNodes, node.Name)
+}
+
+// AssignNodes returns the sorted names of the nodes which should be assigned to the given labels, or nil if they
+// don't have an assignment.
+// Nodes which are already assigned and still match stay assigned, as long as the limit isn't exceeded. Missing nodes
+// are filled up with the matching nodes with the highest rendezvous hash score.
+func AssignNodes(nodes []v1.Node, labels v1beta1.Labels, log logr.Logger) []string {
+	assignment := labels.Spec.Assignment
+	if assignment == nil {
+		return nil
+	}
+
+	var assigned, candidates []v1.Node
+	for i, node := range nodes {
+		if !MatchesNodeSelection(&nodes[i], labels, log) {
+			continue
+		}
+		if contains(labels.Status.AssignedNodes, node.Name) {
+			assigned = append(assigned, node)
+		} else {
+			candidates = append(candidates, node)
+		}
+	}
+
+	limit := assignmentLimit(assignment, len(assigned)+len(candidates))
+	seed := labels.Namespace + "/" + labels.Name
+	sortByScore(assigned, seed)
+	sortByScore(candidates, seed)
+	if len(assigned) > limit {
+		assigned = assigned[:limit]
+	}
+	for _, node := range candidates {
+		if len(assigned) >= limit {
+			break
+		}
+		log.Info("Assigning node", "labels", labels.Name, "node", node.Name)
+		assigned = append(assigned, node)
+	}
+
+	names := make([]string, 0, len(assigned))
+	for _, node := range assigned {
+		names = append(names, node.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// assignmentLimit returns the number of nodes which should be assigned for the given number of matching nodes
+func assignmentLimit(assignment *v1beta1.NodeAssignment, matchingNodes int) int {
+	limit := matchingNodes
+	if assignment.MaxNodes != nil && int(*assignment.MaxNodes) < limit {
+		limit = int(*assignment.MaxNodes)
+	}
+	if assignment.Percentage != nil {
+		// round up, so that a percentage > 0 always assigns a node
+		byPercentage := (matchingNodes*int(*assignment.Percentage) + 99) / 100
+		if byPercentage < limit {
+			limit = byPercentage
+		}
+	}
+	if limit < 0 {
+		limit = 0
+	}
+	return limit
+}
+
+// sortByScore sorts the given nodes by their rendezvous hash score for the given seed, highest score first
+func sortByScore(nodes []v1.Node, seed string) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		scoreI, scoreJ := score(seed, nodes[i]), score(seed, nodes[j])
+		if scoreI != scoreJ {
+			return scoreI > scoreJ
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+}
+
+// score returns the rendezvous hash score of the given node for the given seed. It is based on the node UID,
+// so that a re-created node with the same name doesn't keep its score.
+func score(seed string, node v1.Node) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(seed))
+	_, _ = h.Write([]byte("/"))
+	_, _ = h.Write([]byte(node.UID))
+	return h.Sum64()
+}
@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"regexp"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// compiledObject holds the compiled match expressions and patterns of a CR, for the generation they were compiled for
+type compiledObject struct {
+	generation  int64
+	expressions map[string]compiledExpression
+	patterns    map[string]*regexp.Regexp
+}
+
+// compiledCache caches the compiled match expressions and patterns by CR UID, so every generation is compiled once
+// only. Entries are removed with ForgetCompiled when their CR is deleted.
+var compiledCache = struct {
+	sync.Mutex
+	objects map[types.UID]*compiledObject
+}{objects: map[types.UID]*compiledObject{}}
+
+// ForgetCompiled removes the compiled match expressions and patterns of the CR with the given UID from the cache
+func ForgetCompiled(uid types.UID) {
+	compiledCache.Lock()
+	defer compiledCache.Unlock()
+	delete(compiledCache.objects, uid)
+}
+
+// cachedObject returns the cache entry of the given CR for its current generation, or nil if the CR isn't persisted
+// yet. The cache must be locked.
+func cachedObject(owner metav1.Object) *compiledObject {
+	if owner == nil || owner.GetUID() == "" {
+		return nil
+	}
+	cached, ok := compiledCache.objects[owner.GetUID()]
+	if !ok || cached.generation != owner.GetGeneration() {
+		cached = &compiledObject{
+			generation:  owner.GetGeneration(),
+			expressions: map[string]compiledExpression{},
+			patterns:    map[string]*regexp.Regexp{},
+		}
+		compiledCache.objects[owner.GetUID()] = cached
+	}
+	return cached
+}
+
+// compiledPattern returns the given regular expression of the given CR compiled, from the cache if possible
+func compiledPattern(owner metav1.Object, pattern string) (*regexp.Regexp, error) {
+	compiledCache.Lock()
+	defer compiledCache.Unlock()
+	cached := cachedObject(owner)
+	if cached == nil {
+		return regexp.Compile(pattern)
+	}
+	if re, ok := cached.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	cached.patterns[pattern] = re
+	return re, nil
+}
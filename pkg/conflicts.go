@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"sort"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// LabelConflict describes a label which is set to different values on the same node by different Labels
+type LabelConflict struct {
+	NodeName    string
+	LabelName   string
+	Value       string
+	OtherLabels types.NamespacedName
+	OtherValue  string
+}
+
+// FindConflicts returns all labels of the given Labels, which are set to another value on the given node
+// by any of the given allLabels with higher precedence
+func FindConflicts(node *v1.Node, labels v1beta1.Labels, allLabels []v1beta1.Labels, log logr.Logger) []LabelConflict {
+	if !labels.GetDeletionTimestamp().IsZero() {
+		return nil
+	}
+	nodeLabels := LabelsForNode(node, labels, log)
+	if len(nodeLabels) == 0 {
+		return nil
+	}
+
+	var conflicts []LabelConflict
+	for _, other := range allLabels {
+		if other.Namespace == labels.Namespace && other.Name == labels.Name {
+			continue
+		}
+		if !other.GetDeletionTimestamp().IsZero() || !HasPrecedence(other, labels) {
+			continue
+		}
+		otherNodeLabels := LabelsForNode(node, other, log)
+		for name, value := range nodeLabels {
+			if otherValue, ok := otherNodeLabels[name]; ok && otherValue != value {
+				conflicts = append(conflicts, LabelConflict{
+					NodeName:    node.Name,
+					LabelName:   name,
+					Value:       value,
+					OtherLabels: types.NamespacedName{Namespace: other.Namespace, Name: other.Name},
+					OtherValue:  otherValue,
+				})
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].OtherLabels.String() != conflicts[j].OtherLabels.String() {
+			return conflicts[i].OtherLabels.String() < conflicts[j].OtherLabels.String()
+		}
+		return conflicts[i].LabelName < conflicts[j].LabelName
+	})
+	return conflicts
+}
+
+// SortByPrecedence returns a copy of the given Labels, sorted by precedence, highest precedence first
+func SortByPrecedence(allLabels []v1beta1.Labels) []v1beta1.Labels {
+	sorted := make([]v1beta1.Labels, len(allLabels))
+	copy(sorted, allLabels)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return HasPrecedence(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// HasPrecedence checks if Labels a has precedence over Labels b:
+// - the higher priority wins
+// - on equal priority the older Labels wins
+// - on equal creation time the Labels with the alphabetically first namespace/name wins
+func HasPrecedence(a, b v1beta1.Labels) bool {
+	if a.Spec.Priority != b.Spec.Priority {
+		return a.Spec.Priority > b.Spec.Priority
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
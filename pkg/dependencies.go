@@ -0,0 +1,197 @@
+package pkg
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// DependsOn checks if the node selector, the exclude node selector or the match expression of Labels a references a
+// label, which can be set by Labels b. A match expression which reads labels by computed keys, or all labels at once,
+// depends on all other Labels setting labels.
+func DependsOn(a, b v1beta1.Labels) bool {
+	for _, name := range selectorLabelNames(a.Spec.NodeSelector) {
+		if producesLabel(b, name) {
+			return true
+		}
+	}
+	for _, name := range selectorLabelNames(a.Spec.ExcludeNodeSelector) {
+		if producesLabel(b, name) {
+			return true
+		}
+	}
+	labelKeys, allLabels := expressionLabelKeys(&a, a.Spec.MatchExpression)
+	if allLabels && a.UID != b.UID && producesAnyLabel(b) {
+		return true
+	}
+	for _, name := range labelKeys {
+		if producesLabel(b, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SortByDependencies returns the given Labels in evaluation order: Labels come after all Labels setting labels
+// which they select nodes by. Labels in a dependency cycle are ordered by precedence.
+func SortByDependencies(allLabels []v1beta1.Labels) []v1beta1.Labels {
+	var sorted []v1beta1.Labels
+	for _, component := range dependencyComponents(allLabels) {
+		sorted = append(sorted, component...)
+	}
+	return sorted
+}
+
+// DependencyCycles returns the Labels which depend on each other in a cycle, with the sorted namespace/names of
+// all Labels in their cycle. Deleted Labels are skipped.
+func DependencyCycles(allLabels []v1beta1.Labels) map[types.NamespacedName][]string {
+	var liveLabels []v1beta1.Labels
+	for _, labels := range allLabels {
+		if labels.GetDeletionTimestamp().IsZero() {
+			liveLabels = append(liveLabels, labels)
+		}
+	}
+	cycles := map[types.NamespacedName][]string{}
+	for _, component := range dependencyComponents(liveLabels) {
+		if len(component) == 1 && !DependsOn(component[0], component[0]) {
+			continue
+		}
+		var names []string
+		for _, labels := range component {
+			names = append(names, labelsKey(labels).String())
+		}
+		sort.Strings(names)
+		for _, labels := range component {
+			cycles[labelsKey(labels)] = names
+		}
+	}
+	return cycles
+}
+
+// IsInDependencyCycle checks if the given Labels reported a dependency cycle in its status
+func IsInDependencyCycle(labels v1beta1.Labels) bool {
+	return meta.IsStatusConditionTrue(labels.Status.Conditions, v1beta1.ConditionDependencyCycle)
+}
+
+// dependencyComponents returns the strongly connected components of the dependency graph of the given Labels, with
+// the components of dependencies before the components depending on them, using Tarjan's algorithm
+func dependencyComponents(allLabels []v1beta1.Labels) [][]v1beta1.Labels {
+	sorted := SortByPrecedence(allLabels)
+	index := make([]int, len(sorted))
+	lowLink := make([]int, len(sorted))
+	onStack := make([]bool, len(sorted))
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	var components [][]v1beta1.Labels
+	next := 0
+
+	var visit func(i int)
+	visit = func(i int) {
+		index[i], lowLink[i] = next, next
+		next++
+		stack = append(stack, i)
+		onStack[i] = true
+		for j := range sorted {
+			if !DependsOn(sorted[i], sorted[j]) {
+				continue
+			}
+			if index[j] == -1 {
+				visit(j)
+				if lowLink[j] < lowLink[i] {
+					lowLink[i] = lowLink[j]
+				}
+			} else if onStack[j] && index[j] < lowLink[i] {
+				lowLink[i] = index[j]
+			}
+		}
+		if lowLink[i] != index[i] {
+			return
+		}
+		var members []int
+		for {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[j] = false
+			members = append(members, j)
+			if j == i {
+				break
+			}
+		}
+		sort.Ints(members)
+		component := make([]v1beta1.Labels, 0, len(members))
+		for _, j := range members {
+			component = append(component, sorted[j])
+		}
+		components = append(components, component)
+	}
+
+	for i := range sorted {
+		if index[i] == -1 {
+			visit(i)
+		}
+	}
+	return components
+}
+
+// selectorLabelNames returns the names of the labels referenced by the given label selector
+func selectorLabelNames(selector *metav1.LabelSelector) []string {
+	if selector == nil {
+		return nil
+	}
+	var names []string
+	for name := range selector.MatchLabels {
+		names = append(names, name)
+	}
+	for _, requirement := range selector.MatchExpressions {
+		names = append(names, requirement.Key)
+	}
+	return names
+}
+
+// producesLabel checks if the given Labels can set a label with the given name. Label name templates match every
+// name which they can be expanded to.
+func producesLabel(labels v1beta1.Labels, name string) bool {
+	for labelName := range labels.Spec.Labels {
+		if labelName == name || (templateReference.MatchString(labelName) && matchesTemplate(labels, labelName, name)) {
+			return true
+		}
+	}
+	for _, distribution := range labels.Spec.Distributions {
+		if distribution.Name == name {
+			return true
+		}
+	}
+	if labels.Spec.NodeAddressSelector != nil && labels.Spec.NodeAddressSelector.LabelName == name {
+		return true
+	}
+	return labels.Spec.Inventory != nil && strings.HasPrefix(name, labels.Spec.Inventory.LabelPrefix)
+}
+
+// producesAnyLabel checks if the given Labels can set any label
+func producesAnyLabel(labels v1beta1.Labels) bool {
+	return len(labels.Spec.Labels) > 0 || len(labels.Spec.Distributions) > 0 || labels.Spec.NodeAddressSelector != nil ||
+		labels.Spec.Inventory != nil
+}
+
+// matchesTemplate checks if the given label name is an expansion of the given label name template of the given Labels.
+// Template patterns are cached per Labels generation, since dependencies are checked for all pairs of Labels.
+func matchesTemplate(labels v1beta1.Labels, template, name string) bool {
+	var parts []string
+	for _, part := range templateReference.Split(template, -1) {
+		parts = append(parts, regexp.QuoteMeta(part))
+	}
+	pattern, err := compiledPattern(&labels, "^"+strings.Join(parts, ".*")+"$")
+	return err == nil && pattern.MatchString(name)
+}
+
+func labelsKey(labels v1beta1.Labels) types.NamespacedName {
+	return types.NamespacedName{Namespace: labels.Namespace, Name: labels.Name}
+}
@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"sort"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// DistributeValues returns the balanced distribution of the values of all distributed labels of the given Labels over
+// the given matching nodes.
+// Nodes keep the value recorded in the status of the Labels as long as it is still a candidate value and the
+// distribution stays balanced. Nodes without value, and nodes of values with too many nodes, get the values with too
+// few nodes, so only the minimum number of nodes gets a new value.
+func DistributeValues(nodes []v1.Node, labels v1beta1.Labels, log logr.Logger) []v1beta1.LabelDistributionStatus {
+	if len(labels.Spec.Distributions) == 0 {
+		return nil
+	}
+
+	var nodeNames []string
+	for i, node := range nodes {
+		if MatchesNode(&nodes[i], labels, log) {
+			nodeNames = append(nodeNames, node.Name)
+		}
+	}
+	sort.Strings(nodeNames)
+
+	var result []v1beta1.LabelDistributionStatus
+	for _, distribution := range labels.Spec.Distributions {
+		if len(distribution.Values) == 0 {
+			continue
+		}
+		previous := map[string]string{}
+		for _, status := range labels.Status.Distributions {
+			if status.Name == distribution.Name {
+				previous = status.Nodes
+				break
+			}
+		}
+		nodeValues := distributeValues(nodeNames, distribution.Values, previous)
+		counts := map[string]int32{}
+		for _, value := range nodeValues {
+			counts[value]++
+		}
+		result = append(result, v1beta1.LabelDistributionStatus{
+			Name:        distribution.Name,
+			Nodes:       nodeValues,
+			ValueCounts: counts,
+		})
+	}
+	return result
+}
+
+// distributeValues assigns one of the given values to each of the given sorted node names, keeping the given previous
+// values as far as possible
+func distributeValues(nodeNames []string, values []string, previous map[string]string) map[string]string {
+	// keep the previous values which are still candidates
+	nodesPerValue := map[string][]string{}
+	var unassigned []string
+	for _, nodeName := range nodeNames {
+		if value, ok := previous[nodeName]; ok && contains(values, value) {
+			nodesPerValue[value] = append(nodesPerValue[value], nodeName)
+		} else {
+			unassigned = append(unassigned, nodeName)
+		}
+	}
+
+	// every value gets the same number of nodes, the remaining nodes go to the values which have most nodes already
+	base, remainder := len(nodeNames)/len(values), len(nodeNames)%len(values)
+	byCount := make([]string, len(values))
+	copy(byCount, values)
+	sort.SliceStable(byCount, func(i, j int) bool {
+		return len(nodesPerValue[byCount[i]]) > len(nodesPerValue[byCount[j]])
+	})
+	capacity := map[string]int{}
+	for i, value := range byCount {
+		capacity[value] = base
+		if i < remainder {
+			capacity[value]++
+		}
+	}
+
+	// release nodes of values with too many nodes
+	for _, value := range values {
+		if excess := len(nodesPerValue[value]) - capacity[value]; excess > 0 {
+			kept := nodesPerValue[value][:capacity[value]]
+			unassigned = append(unassigned, nodesPerValue[value][capacity[value]:]...)
+			nodesPerValue[value] = kept
+		}
+	}
+	sort.Strings(unassigned)
+
+	// fill up values with too few nodes, in the order of the candidate values
+	for _, value := range values {
+		for len(nodesPerValue[value]) < capacity[value] && len(unassigned) > 0 {
+			nodesPerValue[value] = append(nodesPerValue[value], unassigned[0])
+			unassigned = unassigned[1:]
+		}
+	}
+
+	result := make(map[string]string, len(nodeNames))
+	for value, names := range nodesPerValue {
+		for _, nodeName := range names {
+			result[nodeName] = value
+		}
+	}
+	return result
+}
+
+// distributedLabelsForNode returns the values of the distributed labels of the given Labels for the given node,
+// as recorded in the status
+func distributedLabelsForNode(node *v1.Node, labels v1beta1.Labels) map[string]string {
+	result := map[string]string{}
+	for _, distribution := range labels.Spec.Distributions {
+		for _, status := range labels.Status.Distributions {
+			if status.Name != distribution.Name {
+				continue
+			}
+			if value, ok := status.Nodes[node.Name]; ok && contains(distribution.Values, value) {
+				result[distribution.Name] = value
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// IsIgnored checks if the ignore annotation is set on the given node, which excludes it from all Labels and
+// OwnedLabels
+func IsIgnored(node *v1.Node) bool {
+	return node.Annotations[v1beta1.AnnotationIgnore] == "true"
+}
+
+// WithoutIgnoredNodes returns the given nodes without the ignored ones
+func WithoutIgnoredNodes(nodes []v1.Node) []v1.Node {
+	result := make([]v1.Node, 0, len(nodes))
+	for i := range nodes {
+		if !IsIgnored(&nodes[i]) {
+			result = append(result, nodes[i])
+		}
+	}
+	return result
+}
+
+// isExcluded checks if the given node is ignored, or matches one of the exclude node name patterns or the exclude
+// node selector of the given Labels
+func isExcluded(node *v1.Node, labels v1beta1.Labels, log logr.Logger) bool {
+	if IsIgnored(node) {
+		return true
+	}
+	for _, pattern := range labels.Spec.ExcludeNodeNamePatterns {
+		if re, err := compileNodeNamePattern(&labels, pattern); err != nil {
+			log.Error(err, "Invalid regular expression, moving on to next exclude pattern")
+		} else if re.MatchString(node.Name) {
+			return true
+		}
+	}
+	return labels.Spec.ExcludeNodeSelector != nil && matchesNodeSelector(node, labels.Spec.ExcludeNodeSelector, log)
+}
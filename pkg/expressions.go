@@ -0,0 +1,265 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/operators"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// nodeVariable is the name of the variable holding the node in match expressions
+const nodeVariable = "node"
+
+// compiledExpression is a compiled match expression, together with the node metadata it reads
+type compiledExpression struct {
+	program    cel.Program
+	references expressionReferences
+}
+
+// expressionReferences describes which node labels and annotations a match expression reads
+type expressionReferences struct {
+	// labelKeys are the sorted keys of the labels, which are read with constant keys
+	labelKeys []string
+	// allLabels is set if labels are read with computed keys, or the labels are used as a whole
+	allLabels bool
+	// annotations is set if any annotation is read
+	annotations bool
+}
+
+// CompileMatchExpression compiles the given CEL expression, which needs to evaluate to a bool.
+// The node is declared as a map of dynamic values, so only the syntax and the result type are checked, not the node
+// fields. Since the node is converted to unstructured data, fields with zero values are missing, and need to be
+// checked with has(), e.g. !has(node.spec.unschedulable) || !node.spec.unschedulable.
+func CompileMatchExpression(expression string) (cel.Program, error) {
+	compiled, err := compileMatchExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.program, nil
+}
+
+// compileMatchExpression compiles the given match expression, and collects the node metadata it reads
+func compileMatchExpression(expression string) (compiledExpression, error) {
+	env, err := cel.NewEnv(cel.Declarations(decls.NewVar(nodeVariable, decls.NewMapType(decls.String, decls.Dyn))))
+	if err != nil {
+		return compiledExpression{}, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return compiledExpression{}, fmt.Errorf("invalid match expression %q: %v", expression, issues.Err())
+	}
+	if !proto.Equal(ast.ResultType(), decls.Bool) && !proto.Equal(ast.ResultType(), decls.Dyn) {
+		return compiledExpression{}, fmt.Errorf("invalid match expression %q: must evaluate to a bool", expression)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return compiledExpression{}, err
+	}
+	references := expressionReferences{}
+	collectReferences(ast.Expr(), &references)
+	sort.Strings(references.labelKeys)
+	return compiledExpression{program: program, references: references}, nil
+}
+
+// collectReferences adds the node labels and annotations read by the given expression to the given references
+func collectReferences(expr *exprpb.Expr, references *expressionReferences) {
+	if expr == nil {
+		return
+	}
+	if path := nodePath(expr); path != nil {
+		addReference(path, references)
+		return
+	}
+	switch kind := expr.GetExprKind().(type) {
+	case *exprpb.Expr_SelectExpr:
+		collectReferences(kind.SelectExpr.GetOperand(), references)
+	case *exprpb.Expr_CallExpr:
+		call := kind.CallExpr
+		args := call.GetArgs()
+		if (call.GetFunction() == operators.In || call.GetFunction() == operators.OldIn) && len(args) == 2 {
+			// "key" in node.metadata.labels
+			if key, ok := constantString(args[0]); ok && isMetadataField(nodePath(args[1]), "labels") {
+				references.labelKeys = append(references.labelKeys, key)
+				return
+			}
+		}
+		collectReferences(call.GetTarget(), references)
+		for _, arg := range args {
+			collectReferences(arg, references)
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, element := range kind.ListExpr.GetElements() {
+			collectReferences(element, references)
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range kind.StructExpr.GetEntries() {
+			collectReferences(entry.GetMapKey(), references)
+			collectReferences(entry.GetValue(), references)
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comprehension := kind.ComprehensionExpr
+		collectReferences(comprehension.GetIterRange(), references)
+		collectReferences(comprehension.GetAccuInit(), references)
+		collectReferences(comprehension.GetLoopCondition(), references)
+		collectReferences(comprehension.GetLoopStep(), references)
+		collectReferences(comprehension.GetResult(), references)
+	}
+}
+
+// nodePath returns the field names and constant keys by which the given expression selects a value of the node
+// variable, e.g. [node metadata labels example.com/zone] for node.metadata.labels["example.com/zone"].
+// It returns nil if the expression isn't such a selection.
+func nodePath(expr *exprpb.Expr) []string {
+	switch kind := expr.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		if kind.IdentExpr.GetName() == nodeVariable {
+			return []string{nodeVariable}
+		}
+	case *exprpb.Expr_SelectExpr:
+		if path := nodePath(kind.SelectExpr.GetOperand()); path != nil {
+			return append(path, kind.SelectExpr.GetField())
+		}
+	case *exprpb.Expr_CallExpr:
+		args := kind.CallExpr.GetArgs()
+		if kind.CallExpr.GetFunction() != operators.Index || len(args) != 2 {
+			return nil
+		}
+		key, ok := constantString(args[1])
+		if !ok {
+			return nil
+		}
+		if path := nodePath(args[0]); path != nil {
+			return append(path, key)
+		}
+	}
+	return nil
+}
+
+// addReference adds the node labels and annotations read by selecting the given node path to the given references.
+// Selecting the node or its metadata as a whole reads all labels and annotations.
+func addReference(path []string, references *expressionReferences) {
+	switch {
+	case len(path) < 3:
+		references.allLabels = true
+		references.annotations = true
+	case isMetadataField(path, "labels"):
+		references.allLabels = true
+	case isMetadataField(path[:3], "labels"):
+		references.labelKeys = append(references.labelKeys, path[3])
+	case isMetadataField(path[:3], "annotations"):
+		references.annotations = true
+	}
+}
+
+// isMetadataField checks if the given node path selects the given metadata field as a whole
+func isMetadataField(path []string, field string) bool {
+	return len(path) == 3 && path[1] == "metadata" && path[2] == field
+}
+
+// constantString returns the value of the given expression, if it is a string constant
+func constantString(expr *exprpb.Expr) (string, bool) {
+	constant := expr.GetConstExpr()
+	if constant == nil {
+		return "", false
+	}
+	if _, ok := constant.GetConstantKind().(*exprpb.Constant_StringValue); !ok {
+		return "", false
+	}
+	return constant.GetStringValue(), true
+}
+
+// ExpressionUsesAnnotations checks if the given match expression of the given CR reads node annotations.
+// Invalid expressions don't read anything.
+func ExpressionUsesAnnotations(owner metav1.Object, expression string) bool {
+	if expression == "" {
+		return false
+	}
+	compiled, err := matchExpression(owner, expression)
+	return err == nil && compiled.references.annotations
+}
+
+// expressionLabelKeys returns the keys of the node labels, which are read by the given match expression of the given
+// CR, or true if it reads any label. Invalid expressions don't read anything.
+func expressionLabelKeys(owner metav1.Object, expression string) ([]string, bool) {
+	if expression == "" {
+		return nil, false
+	}
+	compiled, err := matchExpression(owner, expression)
+	if err != nil {
+		return nil, false
+	}
+	return compiled.references.labelKeys, compiled.references.allLabels
+}
+
+// EvaluateMatchExpression evaluates the given match expression of the given CR against the given node.
+// The compiled expression is cached for the generation of the CR.
+func EvaluateMatchExpression(node *v1.Node, owner metav1.Object, expression string) (bool, error) {
+	compiled, err := matchExpression(owner, expression)
+	if err != nil {
+		return false, err
+	}
+	program := compiled.program
+	nodeObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(node)
+	if err != nil {
+		return false, err
+	}
+	val, _, err := program.Eval(map[string]interface{}{nodeVariable: nodeObj})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate match expression for node %s: %v", node.Name, err)
+	}
+	match, ok := val.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("match expression evaluated to %v instead of a bool for node %s", val.Value(), node.Name)
+	}
+	return match, nil
+}
+
+// MatchExpressionError returns the first error of evaluating the given match expression of the given CR against the
+// given nodes, together with the number of failed nodes, or nil if the expression could be evaluated for all nodes
+func MatchExpressionError(nodes []v1.Node, owner metav1.Object, expression string) error {
+	if expression == "" {
+		return nil
+	}
+	var firstErr error
+	failed := 0
+	for i := range nodes {
+		if _, err := EvaluateMatchExpression(&nodes[i], owner, expression); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if failed > 1 {
+		return fmt.Errorf("%v, and %d more nodes", firstErr, failed-1)
+	}
+	return firstErr
+}
+
+// matchExpression returns the compiled match expression of the given CR, from the cache if possible
+func matchExpression(owner metav1.Object, expression string) (compiledExpression, error) {
+	compiledCache.Lock()
+	defer compiledCache.Unlock()
+	cached := cachedObject(owner)
+	if cached == nil {
+		// not persisted yet
+		return compileMatchExpression(expression)
+	}
+	if compiled, ok := cached.expressions[expression]; ok {
+		return compiled, nil
+	}
+	compiled, err := compileMatchExpression(expression)
+	if err != nil {
+		return compiledExpression{}, err
+	}
+	cached.expressions[expression] = compiled
+	return compiled, nil
+}
@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// hostnameLabel is the well-known node label containing the hostname
+const hostnameLabel = "kubernetes.io/hostname"
+
+// ParseInventory parses the given inventory table in the format of the given inventory source, and returns its rows
+// as maps of column names to values
+func ParseInventory(data string, source v1beta1.InventorySource) ([]map[string]string, error) {
+	switch source.Format {
+	case "csv":
+		return parseCSVInventory(data)
+	case "yaml":
+		var rows []map[string]string
+		if err := yaml.NewYAMLOrJSONDecoder(strings.NewReader(data), 4096).Decode(&rows); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("invalid yaml inventory: %v", err)
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("unsupported inventory format %q", source.Format)
+	}
+}
+
+// parseCSVInventory parses the given CSV table, the first row contains the column names
+func parseCSVInventory(data string) ([]map[string]string, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv inventory: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[strings.TrimSpace(column)] = strings.TrimSpace(record[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// InventoryStatus returns the inventory labels of all given nodes matching the given Labels, sorted by node name.
+// Nodes without inventory row are skipped.
+func InventoryStatus(nodes []v1.Node, labels v1beta1.Labels, rows []map[string]string, log logr.Logger) []v1beta1.NodeInventory {
+	source := labels.Spec.Inventory
+	if source == nil {
+		return nil
+	}
+
+	var result []v1beta1.NodeInventory
+	for i, node := range nodes {
+		if !MatchesNode(&nodes[i], labels, log) {
+			continue
+		}
+		row := findInventoryRow(&nodes[i], *source, rows)
+		if row == nil {
+			continue
+		}
+		nodeLabels := map[string]string{}
+		for column, value := range row {
+			if column == source.KeyColumn || (len(source.Columns) > 0 && !contains(source.Columns, column)) {
+				continue
+			}
+			name := source.LabelPrefix + column
+			if err := validateLabel(name, value); err != nil {
+				log.Info("Skipping invalid inventory label", "node", node.Name, "column", column, "reason", err.Error())
+				continue
+			}
+			nodeLabels[name] = value
+		}
+		result = append(result, v1beta1.NodeInventory{
+			NodeName: node.Name,
+			Labels:   nodeLabels,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NodeName < result[j].NodeName
+	})
+	return result
+}
+
+// findInventoryRow returns the first of the given rows, whose key column identifies the given node
+func findInventoryRow(node *v1.Node, source v1beta1.InventorySource, rows []map[string]string) map[string]string {
+	identifiers := nodeIdentifiers(node, source.MatchBy)
+	for _, row := range rows {
+		key, ok := row[source.KeyColumn]
+		if !ok || key == "" {
+			continue
+		}
+		for _, identifier := range identifiers {
+			if strings.EqualFold(key, identifier) {
+				return row
+			}
+		}
+	}
+	return nil
+}
+
+// nodeIdentifiers returns the values of the given node, which can identify it in an inventory
+func nodeIdentifiers(node *v1.Node, matchBy string) []string {
+	switch matchBy {
+	case v1beta1.InventoryMatchByProviderID:
+		if node.Spec.ProviderID == "" {
+			return nil
+		}
+		return []string{node.Spec.ProviderID}
+	case v1beta1.InventoryMatchByAddress:
+		var addresses []string
+		for _, address := range node.Status.Addresses {
+			addresses = append(addresses, address.Address)
+		}
+		return addresses
+	default:
+		identifiers := []string{node.Name}
+		if hostname, ok := node.Labels[hostnameLabel]; ok {
+			identifiers = append(identifiers, hostname)
+		}
+		return identifiers
+	}
+}
+
+// inventoryLabelsForNode returns the inventory labels of the given Labels for the given node, as recorded in the status
+func inventoryLabelsForNode(node *v1.Node, labels v1beta1.Labels) map[string]string {
+	if labels.Spec.Inventory == nil {
+		return nil
+	}
+	for _, inventory := range labels.Status.Inventory {
+		if inventory.NodeName == node.Name {
+			return inventory.Labels
+		}
+	}
+	return nil
+}
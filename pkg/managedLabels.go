@@ -0,0 +1,85 @@
+package pkg
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// managedKeysSeparator separates the label and annotation names in the managed labels and annotations annotations.
+// It can't be part of a label or annotation name.
+const managedKeysSeparator = ","
+
+// GetManagedLabels returns the names of the labels which were applied to the given node by the operator
+func GetManagedLabels(node *v1.Node) []string {
+	return getManagedKeys(node, v1beta1.AnnotationManagedLabels)
+}
+
+// SetManagedLabels records the given label names as applied by the operator on the given node,
+// and returns true if the node was modified
+func SetManagedLabels(node *v1.Node, labelDomainNames []string) bool {
+	return setManagedKeys(node, v1beta1.AnnotationManagedLabels, labelDomainNames)
+}
+
+// getManagedKeys returns the names recorded in the given annotation of the given node
+func getManagedKeys(node *v1.Node, annotationName string) []string {
+	annotation, ok := node.Annotations[annotationName]
+	if !ok || annotation == "" {
+		return nil
+	}
+	return strings.Split(annotation, managedKeysSeparator)
+}
+
+// setManagedKeys records the given names in the given annotation of the given node,
+// and returns true if the node was modified
+func setManagedKeys(node *v1.Node, annotationName string, names []string) bool {
+	sorted := make([]string, len(names))
+	copy(sorted, names)
+	sort.Strings(sorted)
+	annotation := strings.Join(sorted, managedKeysSeparator)
+
+	current, exists := node.Annotations[annotationName]
+	if annotation == "" {
+		if !exists {
+			return false
+		}
+		delete(node.Annotations, annotationName)
+		return true
+	}
+	if exists && current == annotation {
+		return false
+	}
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	node.Annotations[annotationName] = annotation
+	return true
+}
+
+// RemoveManagedLabels removes all managed labels from the node, which aren't covered by any of the given Labels anymore,
+// and returns true if the node was modified
+func RemoveManagedLabels(node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	log.Info("Checking managed labels", "node", node.Name)
+	nodeModified := false
+	var stillManaged []string
+	for _, labelDomainName := range GetManagedLabels(node) {
+		if IsCoveredByAll(node, labelDomainName, allLabels, log) {
+			stillManaged = append(stillManaged, labelDomainName)
+			continue
+		}
+		if _, ok := node.Labels[labelDomainName]; ok {
+			log.Info("Deleting uncovered managed label", "node", node.Name, "labelName", labelDomainName)
+			delete(node.Labels, labelDomainName)
+		}
+		nodeModified = true
+	}
+	if nodeModified {
+		SetManagedLabels(node, stillManaged)
+	}
+	return nodeModified
+}
@@ -0,0 +1,213 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// compileSourcePattern compiles the given source pattern of a LabelMigration with start and end anchors
+func compileSourcePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(fmt.Sprintf("%s%s%s", "^", pattern, "$"))
+}
+
+// MigrationSourceLabels returns the sorted names of the labels of the given node, which are migrated by the given
+// LabelMigration. Nodes which don't match the node selector of the LabelMigration have no source labels.
+func MigrationSourceLabels(node *v1.Node, migration v1beta1.LabelMigration, log logr.Logger) []string {
+	if migration.Spec.NodeSelector != nil && !matchesNodeSelector(node, migration.Spec.NodeSelector, log) {
+		return nil
+	}
+	re, err := compileSourcePattern(migration.Spec.SourcePattern)
+	if err != nil {
+		log.Error(err, "Invalid regular expression, skipping migration", "labelMigration", migration.Name)
+		return nil
+	}
+	var sourceLabels []string
+	for name := range node.Labels {
+		if name != migration.Spec.Target && re.MatchString(name) {
+			sourceLabels = append(sourceLabels, name)
+		}
+	}
+	sort.Strings(sourceLabels)
+	return sourceLabels
+}
+
+// migratedTargetValue returns the value of the target label of the given LabelMigration on the given node, and false
+// if the node doesn't have the target label or doesn't match the node selector of the LabelMigration
+func migratedTargetValue(node *v1.Node, migration v1beta1.LabelMigration, log logr.Logger) (string, bool) {
+	if migration.Spec.NodeSelector != nil && !matchesNodeSelector(node, migration.Spec.NodeSelector, log) {
+		return "", false
+	}
+	value, ok := node.Labels[migration.Spec.Target]
+	return value, ok
+}
+
+// migratedValue returns the value of the target label of the given LabelMigration for the given source value
+func migratedValue(migration v1beta1.LabelMigration, value string) string {
+	if mapped, ok := migration.Spec.ValueMapping[value]; ok {
+		return mapped
+	}
+	return value
+}
+
+// MigrationNodes returns the migration state of the given nodes for the given LabelMigration, sorted by node name.
+// Nodes are migrated while they have source labels. Nodes whose source labels are gone are kept as removed, as long as
+// they are recorded in the status of the LabelMigration or have the target label, since the status lists a limited
+// number of nodes only. The Labels covering the source labels are determined per node with CoveringLabels.
+func MigrationNodes(nodes []v1.Node, migration v1beta1.LabelMigration, allOwnedLabels []v1beta1.OwnedLabels, allLabels []v1beta1.Labels, dryRun, limited bool, log logr.Logger) []v1beta1.NodeMigration {
+	recorded := map[string]v1beta1.NodeMigration{}
+	for _, nodeMigration := range migration.Status.Nodes {
+		recorded[nodeMigration.NodeName] = nodeMigration
+	}
+
+	var result []v1beta1.NodeMigration
+	for i, node := range nodes {
+		sourceLabels := MigrationSourceLabels(&nodes[i], migration, log)
+		if len(sourceLabels) == 0 {
+			if nodeMigration, ok := recorded[node.Name]; ok {
+				nodeMigration.State = v1beta1.NodeMigrationRemoved
+				result = append(result, nodeMigration)
+			} else if value, ok := migratedTargetValue(&nodes[i], migration, log); ok {
+				result = append(result, v1beta1.NodeMigration{
+					NodeName:     node.Name,
+					SourceLabels: []string{},
+					Value:        value,
+					State:        v1beta1.NodeMigrationRemoved,
+				})
+			}
+			continue
+		}
+		coveringLabels := CoveringLabels(node.Name, allLabels, dryRun, limited)
+		result = append(result, v1beta1.NodeMigration{
+			NodeName:     node.Name,
+			SourceLabels: sourceLabels,
+			Value:        migratedValue(migration, node.Labels[sourceLabels[0]]),
+			State:        nodeMigrationState(&nodes[i], migration, sourceLabels, allOwnedLabels, coveringLabels, log),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NodeName < result[j].NodeName
+	})
+	return result
+}
+
+// nodeMigrationState returns the migration state of the given node with the given source labels
+func nodeMigrationState(node *v1.Node, migration v1beta1.LabelMigration, sourceLabels []string, allOwnedLabels []v1beta1.OwnedLabels, allLabels []v1beta1.Labels, log logr.Logger) v1beta1.NodeMigrationState {
+	value := migratedValue(migration, node.Labels[sourceLabels[0]])
+	for _, name := range sourceLabels[1:] {
+		if migratedValue(migration, node.Labels[name]) != value {
+			log.Info("Source labels map to different values", "node", node.Name, "labelMigration", migration.Name)
+			return v1beta1.NodeMigrationConflict
+		}
+	}
+	if targetValue, ok := node.Labels[migration.Spec.Target]; !ok {
+		return v1beta1.NodeMigrationPending
+	} else if targetValue != value {
+		return v1beta1.NodeMigrationConflict
+	}
+
+	if len(removableSourceLabels(node, sourceLabels, allOwnedLabels, allLabels, log)) > 0 {
+		return v1beta1.NodeMigrationCopied
+	}
+	for _, name := range sourceLabels {
+		if IsCoveredByAll(node, name, allLabels, log) {
+			return v1beta1.NodeMigrationCovered
+		}
+	}
+	return v1beta1.NodeMigrationNotOwned
+}
+
+// removableSourceLabels returns the given source labels, which are owned by any of the given OwnedLabels and not
+// covered by any of the given Labels
+func removableSourceLabels(node *v1.Node, sourceLabels []string, allOwnedLabels []v1beta1.OwnedLabels, allLabels []v1beta1.Labels, log logr.Logger) []string {
+	allOwnedLabels = OwnedLabelsForNode(node, allOwnedLabels, log)
+	var removable []string
+	for _, name := range sourceLabels {
+		for _, ownedLabel := range allOwnedLabels {
+			if IsOwnedLabel(name, ownedLabel, log) && !IsCoveredByAll(node, name, allLabels, log) {
+				removable = append(removable, name)
+				break
+			}
+		}
+	}
+	return removable
+}
+
+// MigrationPhase returns the phase of a LabelMigration with the given node migration states:
+// it is copying as long as any node misses the target label, and removing as long as any node has removable
+// source labels
+func MigrationPhase(nodeMigrations []v1beta1.NodeMigration) v1beta1.LabelMigrationPhase {
+	phase := v1beta1.MigrationPhaseCompleted
+	for _, nodeMigration := range nodeMigrations {
+		switch nodeMigration.State {
+		case v1beta1.NodeMigrationPending, v1beta1.NodeMigrationConflict:
+			return v1beta1.MigrationPhaseCopying
+		case v1beta1.NodeMigrationCopied:
+			phase = v1beta1.MigrationPhaseRemoving
+		}
+	}
+	return phase
+}
+
+// CopyMigratedLabel sets the target label of the given LabelMigration on the given node, if it is still missing and
+// all source labels map to the same value. It returns true if the node was modified.
+func CopyMigratedLabel(node *v1.Node, migration v1beta1.LabelMigration, log logr.Logger) bool {
+	sourceLabels := MigrationSourceLabels(node, migration, log)
+	if len(sourceLabels) == 0 ||
+		nodeMigrationState(node, migration, sourceLabels, nil, nil, log) != v1beta1.NodeMigrationPending {
+		return false
+	}
+	value := migratedValue(migration, node.Labels[sourceLabels[0]])
+	log.Info("Copying migrated label", "node", node.Name, "labelName", migration.Spec.Target, "labelValue", value)
+	node.Labels[migration.Spec.Target] = value
+	return true
+}
+
+// RemoveMigratedLabels removes the source labels of the given LabelMigration from the given node, if they are owned
+// and uncovered, and the target label is set to the migrated value. It returns true if the node was modified.
+func RemoveMigratedLabels(node *v1.Node, migration v1beta1.LabelMigration, allOwnedLabels []v1beta1.OwnedLabels, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	sourceLabels := MigrationSourceLabels(node, migration, log)
+	if len(sourceLabels) == 0 ||
+		nodeMigrationState(node, migration, sourceLabels, allOwnedLabels, allLabels, log) != v1beta1.NodeMigrationCopied {
+		return false
+	}
+	for _, name := range removableSourceLabels(node, sourceLabels, allOwnedLabels, allLabels, log) {
+		log.Info("Deleting migrated source label", "node", node.Name, "labelName", name)
+		delete(node.Labels, name)
+	}
+	return true
+}
+
+// MigratingLabels returns the labels of the given node, which must be kept for any of the given LabelMigrations:
+// the source labels, which are still copied, must not be removed before every matching node has the target label,
+// and the target label is kept as long as the LabelMigration exists.
+func MigratingLabels(node *v1.Node, allMigrations []v1beta1.LabelMigration, log logr.Logger) []string {
+	var migrating []string
+	for _, migration := range allMigrations {
+		if !migration.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if _, ok := migratedTargetValue(node, migration, log); ok {
+			migrating = append(migrating, migration.Spec.Target)
+		}
+		if migration.Status.Phase != "" && migration.Status.Phase != v1beta1.MigrationPhaseCopying {
+			continue
+		}
+		migrating = append(migrating, MigrationSourceLabels(node, migration, log)...)
+	}
+	return migrating
+}
+
+// IsMigrationNode checks if the given node has source labels or the target label of the given LabelMigration
+func IsMigrationNode(node *v1.Node, migration v1beta1.LabelMigration, log logr.Logger) bool {
+	if _, ok := migratedTargetValue(node, migration, log); ok {
+		return true
+	}
+	return len(MigrationSourceLabels(node, migration, log)) > 0
+}
@@ -0,0 +1,54 @@
+package pkg
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// matchesNodeConditionRequirement checks if the given node has the condition of the given requirement with the
+// required status, and if it had this status for at least the minimum duration at the given time
+func matchesNodeConditionRequirement(node *v1.Node, requirement v1beta1.NodeConditionRequirement, now time.Time) bool {
+	remaining, ok := remainingConditionDuration(node, requirement, now)
+	return ok && remaining <= 0
+}
+
+// remainingConditionDuration returns the time which is left at the given time until the condition of the given
+// requirement had the required status for the minimum duration. It returns false if the given node doesn't have the
+// condition with the required status.
+func remainingConditionDuration(node *v1.Node, requirement v1beta1.NodeConditionRequirement, now time.Time) (time.Duration, bool) {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != requirement.Type {
+			continue
+		}
+		if condition.Status != requirement.Status {
+			return 0, false
+		}
+		if requirement.MinDuration == nil {
+			return 0, true
+		}
+		return condition.LastTransitionTime.Add(requirement.MinDuration.Duration).Sub(now), true
+	}
+	return 0, false
+}
+
+// NextConditionMatch returns the time after which the given node meets a node condition requirement of the given
+// Labels, because the condition has the required status already, but not for the minimum duration yet.
+// It returns 0 if no requirement will be met without another change of the node conditions.
+func NextConditionMatch(node *v1.Node, allLabels []v1beta1.Labels, now time.Time) time.Duration {
+	var next time.Duration
+	for _, labels := range allLabels {
+		for _, requirement := range labels.Spec.NodeConditionRequirements {
+			remaining, ok := remainingConditionDuration(node, requirement, now)
+			if !ok || remaining <= 0 {
+				continue
+			}
+			if next == 0 || remaining < next {
+				next = remaining
+			}
+		}
+	}
+	return next
+}
@@ -0,0 +1,287 @@
+package pkg
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// nodeFields defines the node fields which can be used in node field selector terms
+var nodeFields = map[string]func(node *v1.Node) string{
+	"status.nodeInfo.architecture":            func(node *v1.Node) string { return node.Status.NodeInfo.Architecture },
+	"status.nodeInfo.operatingSystem":         func(node *v1.Node) string { return node.Status.NodeInfo.OperatingSystem },
+	"status.nodeInfo.kernelVersion":           func(node *v1.Node) string { return node.Status.NodeInfo.KernelVersion },
+	"status.nodeInfo.osImage":                 func(node *v1.Node) string { return node.Status.NodeInfo.OSImage },
+	"status.nodeInfo.kubeletVersion":          func(node *v1.Node) string { return node.Status.NodeInfo.KubeletVersion },
+	"status.nodeInfo.containerRuntimeVersion": func(node *v1.Node) string { return node.Status.NodeInfo.ContainerRuntimeVersion },
+	"spec.providerID":                         func(node *v1.Node) string { return node.Spec.ProviderID },
+}
+
+// MatchesNode checks if the given node matches all node selection criteria of the given labels, and is assigned
+// to them
+func MatchesNode(node *v1.Node, labels v1beta1.Labels, log logr.Logger) bool {
+	return MatchesNodeSelection(node, labels, log) && IsAssigned(node, labels)
+}
+
+// MatchesNodeSelection checks if the given node matches all node selection criteria of the given labels,
+// without considering the assignment
+func MatchesNodeSelection(node *v1.Node, labels v1beta1.Labels, log logr.Logger) bool {
+	spec := labels.Spec
+	if !hasNodeSelection(labels) {
+		log.Info("No node selection criteria configured, no node matches", "labels", labels.Name)
+		return false
+	}
+	if isExcluded(node, labels, log) {
+		return false
+	}
+	if IsInDependencyCycle(labels) {
+		log.Info("Labels is in a dependency cycle, no node matches", "labels", labels.Name)
+		return false
+	}
+	now := time.Now()
+	if !IsActive(labels, now) {
+		log.Info("Labels isn't active at this time, no node matches", "labels", labels.Name)
+		return false
+	}
+	if len(spec.NodeNamePatterns) > 0 && !MatchesNodeName(node.Name, labels, log) {
+		return false
+	}
+	if spec.NodeSelector != nil && !matchesNodeSelector(node, spec.NodeSelector, log) {
+		return false
+	}
+	if len(spec.NodeFieldSelectorTerms) > 0 && !matchesNodeFieldSelectorTerms(node, spec.NodeFieldSelectorTerms, log) {
+		return false
+	}
+	if spec.NodeAddressSelector != nil && matchNodeAddress(node, spec.NodeAddressSelector, log) == "" {
+		return false
+	}
+	for _, requirement := range spec.NodeResourceRequirements {
+		match, err := matchesNodeResourceRequirement(node, requirement)
+		if err != nil {
+			log.Error(err, "Invalid node resource requirement, node doesn't match")
+			return false
+		}
+		if !match {
+			return false
+		}
+	}
+	for _, requirement := range spec.NodeConditionRequirements {
+		if !matchesNodeConditionRequirement(node, requirement, now) {
+			return false
+		}
+	}
+	if spec.MatchExpression != "" {
+		match, err := EvaluateMatchExpression(node, &labels, spec.MatchExpression)
+		if err != nil {
+			// reported in the status by the Labels reconciler
+			log.Info("Match expression can't be evaluated, node doesn't match", "node", node.Name, "reason", err.Error())
+			return false
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+// OwnedLabelsForNode returns the given OwnedLabels whose match expression matches the given node
+func OwnedLabelsForNode(node *v1.Node, allOwnedLabels []v1beta1.OwnedLabels, log logr.Logger) []v1beta1.OwnedLabels {
+	var result []v1beta1.OwnedLabels
+	for i, ownedLabels := range allOwnedLabels {
+		if ownedLabels.Spec.MatchExpression == "" {
+			result = append(result, ownedLabels)
+			continue
+		}
+		match, err := EvaluateMatchExpression(node, &allOwnedLabels[i], ownedLabels.Spec.MatchExpression)
+		if err != nil {
+			// reported in the status by the OwnedLabels reconciler
+			log.Info("Match expression can't be evaluated, node doesn't match", "node", node.Name, "ownedLabels", ownedLabels.Name, "reason", err.Error())
+			continue
+		}
+		if match {
+			result = append(result, ownedLabels)
+		}
+	}
+	return result
+}
+
+// MatchesNodeName checks if one of the node name patterns of the given labels matches the given nodeName
+func MatchesNodeName(nodeName string, labels v1beta1.Labels, log logr.Logger) bool {
+	re, _ := matchNodeName(nodeName, labels, log)
+	return re != nil
+}
+
+// matchNodeName returns the first node name pattern of the given labels which matches the given nodeName,
+// together with the indexes of its submatches. It returns nil if no pattern matches.
+func matchNodeName(nodeName string, labels v1beta1.Labels, log logr.Logger) (*regexp.Regexp, []int) {
+	for _, nodeNamePattern := range labels.Spec.NodeNamePatterns {
+		re, err := compileNodeNamePattern(&labels, nodeNamePattern)
+		if err != nil {
+			log.Error(err, "Invalid regular expression, moving on to next pattern")
+			continue
+		}
+		if submatches := re.FindStringSubmatchIndex(nodeName); submatches != nil {
+			return re, submatches
+		}
+	}
+	return nil, nil
+}
+
+// hasNodeSelection checks if any node selection criteria are configured in the given Labels. The assignment and the
+// inventory only narrow down the matching nodes, and aren't node selection criteria on their own.
+func hasNodeSelection(labels v1beta1.Labels) bool {
+	spec := labels.Spec
+	return len(spec.NodeNamePatterns) > 0 || spec.NodeSelector != nil || len(spec.NodeFieldSelectorTerms) > 0 ||
+		spec.NodeAddressSelector != nil || len(spec.NodeResourceRequirements) > 0 ||
+		len(spec.NodeConditionRequirements) > 0 || spec.MatchExpression != ""
+}
+
+// compileNodeNamePattern compiles the given node name pattern of the given CR, with start and end anchors added.
+// Patterns are cached per CR generation, since they are matched against every node.
+func compileNodeNamePattern(owner metav1.Object, nodeNamePattern string) (*regexp.Regexp, error) {
+	return compiledPattern(owner, fmt.Sprintf("%s%s%s", "^", nodeNamePattern, "$"))
+}
+
+func matchesNodeSelector(node *v1.Node, nodeSelector *metav1.LabelSelector, log logr.Logger) bool {
+	selector, err := metav1.LabelSelectorAsSelector(nodeSelector)
+	if err != nil {
+		log.Error(err, "Invalid node selector")
+		return false
+	}
+	return selector.Matches(k8slabels.Set(node.Labels))
+}
+
+func matchesNodeFieldSelectorTerms(node *v1.Node, terms []v1beta1.NodeFieldSelectorTerm, log logr.Logger) bool {
+	for _, term := range terms {
+		if matchesNodeFieldSelectorTerm(node, term, log) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesNodeFieldSelectorTerm(node *v1.Node, term v1beta1.NodeFieldSelectorTerm, log logr.Logger) bool {
+	for _, requirement := range term.MatchFields {
+		match, err := matchesNodeFieldRequirement(node, requirement)
+		if err != nil {
+			log.Error(err, "Invalid node field requirement, term doesn't match")
+			return false
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesNodeFieldRequirement(node *v1.Node, requirement v1.NodeSelectorRequirement) (bool, error) {
+	if err := validateNodeFieldRequirement(requirement); err != nil {
+		return false, err
+	}
+	value := nodeFields[requirement.Key](node)
+	switch requirement.Operator {
+	case v1.NodeSelectorOpIn:
+		return contains(requirement.Values, value), nil
+	case v1.NodeSelectorOpNotIn:
+		return !contains(requirement.Values, value), nil
+	case v1.NodeSelectorOpExists:
+		return value != "", nil
+	default:
+		// v1.NodeSelectorOpDoesNotExist
+		return value == "", nil
+	}
+}
+
+func validateNodeFieldRequirement(requirement v1.NodeSelectorRequirement) error {
+	if _, ok := nodeFields[requirement.Key]; !ok {
+		return fmt.Errorf("unsupported node field %q", requirement.Key)
+	}
+	switch requirement.Operator {
+	case v1.NodeSelectorOpIn, v1.NodeSelectorOpNotIn, v1.NodeSelectorOpExists, v1.NodeSelectorOpDoesNotExist:
+		return nil
+	default:
+		return fmt.Errorf("unsupported operator %q for node field %q", requirement.Operator, requirement.Key)
+	}
+}
+
+// matchNodeAddress returns the first CIDR of the given node address selector, which contains an address of the
+// given node with the selected type, or an empty string if no CIDR matches
+func matchNodeAddress(node *v1.Node, selector *v1beta1.NodeAddressSelector, log logr.Logger) string {
+	for _, cidr := range selector.CIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Error(err, "Invalid CIDR, moving on to next CIDR", "cidr", cidr)
+			continue
+		}
+		for _, address := range node.Status.Addresses {
+			if address.Type != selector.Type {
+				continue
+			}
+			if ip := net.ParseIP(address.Address); ip != nil && network.Contains(ip) {
+				return cidr
+			}
+		}
+	}
+	return ""
+}
+
+func matchesNodeResourceRequirement(node *v1.Node, requirement v1beta1.NodeResourceRequirement) (bool, error) {
+	if err := validateNodeResourceRequirement(requirement); err != nil {
+		return false, err
+	}
+	resources := node.Status.Allocatable
+	if requirement.Source == v1beta1.ResourceSourceCapacity {
+		resources = node.Status.Capacity
+	}
+	// resources which aren't reported by the node are compared as 0
+	quantity := resources[requirement.Name]
+	cmp := quantity.Cmp(requirement.Value)
+	switch requirement.Operator {
+	case v1beta1.ResourceOpGt:
+		return cmp > 0, nil
+	case v1beta1.ResourceOpGe:
+		return cmp >= 0, nil
+	case v1beta1.ResourceOpLt:
+		return cmp < 0, nil
+	case v1beta1.ResourceOpLe:
+		return cmp <= 0, nil
+	default:
+		// v1beta1.ResourceOpEq
+		return cmp == 0, nil
+	}
+}
+
+func validateNodeResourceRequirement(requirement v1beta1.NodeResourceRequirement) error {
+	if requirement.Name == "" {
+		return fmt.Errorf("missing resource name")
+	}
+	switch requirement.Source {
+	case "", v1beta1.ResourceSourceCapacity, v1beta1.ResourceSourceAllocatable:
+	default:
+		return fmt.Errorf("unsupported source %q for resource %q", requirement.Source, requirement.Name)
+	}
+	switch requirement.Operator {
+	case v1beta1.ResourceOpGt, v1beta1.ResourceOpGe, v1beta1.ResourceOpLt, v1beta1.ResourceOpLe, v1beta1.ResourceOpEq:
+		return nil
+	default:
+		return fmt.Errorf("unsupported operator %q for resource %q", requirement.Operator, requirement.Name)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -2,21 +2,33 @@ package pkg
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
 
 	"github.com/go-logr/logr"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/openshift-kni/node-label-operator/api/v1beta1"
 )
 
 // RemoveOwnedLabels removes all uncovered owned labels from the node and return true if the node was modified
 func RemoveOwnedLabels(node *v1.Node, allOwnedLabels []v1beta1.OwnedLabels, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	return RemoveOwnedLabelsExcept(node, allOwnedLabels, allLabels, nil, log)
+}
+
+// RemoveOwnedLabelsExcept removes all uncovered owned labels from the node, except the given kept labels,
+// and return true if the node was modified
+func RemoveOwnedLabelsExcept(node *v1.Node, allOwnedLabels []v1beta1.OwnedLabels, allLabels []v1beta1.Labels, keep []string, log logr.Logger) bool {
 	// check if we have owned labels on the node
 	log.Info("Checking owned labels", "node", node.Name)
 	nodeModified := false
+	allOwnedLabels = OwnedLabelsForNode(node, allOwnedLabels, log)
 	for labelDomainName := range node.Labels {
+		if contains(keep, labelDomainName) {
+			log.Info("Keeping owned label", "labelDomainName", labelDomainName)
+			continue
+		}
 		// check if we own this label
 		for _, ownedLabel := range allOwnedLabels {
 			if !IsOwnedLabel(labelDomainName, ownedLabel, log) {
@@ -24,7 +36,7 @@ func RemoveOwnedLabels(node *v1.Node, allOwnedLabels []v1beta1.OwnedLabels, allL
 			}
 			// we own this label
 			// check if it is still covered by a label rule
-			if !IsCoveredByAll(node.Name, labelDomainName, allLabels, log) {
+			if !IsCoveredByAll(node, labelDomainName, allLabels, log) {
 				// we need to remove the label
 				log.Info("Deleting uncovered owned label")
 				delete(node.Labels, labelDomainName)
@@ -35,44 +47,106 @@ func RemoveOwnedLabels(node *v1.Node, allOwnedLabels []v1beta1.OwnedLabels, allL
 	return nodeModified
 }
 
-// AddAllLabels adds the labels configured in the rules of the given Labels to the given node
+// AddAllLabels adds the labels configured in the rules of the given Labels to the given node,
+// and records them as managed labels.
+// If multiple Labels set the same label to different values, the Labels with the highest precedence wins.
 func AddAllLabels(node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	return ApplyLabels(node, DesiredLabels(node, allLabels, log), log)
+}
+
+// ApplyLabels adds the given desired labels to the given node, and records them as managed labels.
+// Labels which were already set to the desired value by other tools aren't recorded.
+func ApplyLabels(node *v1.Node, desiredLabels map[string]string, log logr.Logger) bool {
+	// keep managed labels which are still on the node, they are removed by RemoveManagedLabels only
+	managedLabels := make([]string, 0, len(desiredLabels))
+	alreadyManaged := map[string]bool{}
+	for _, name := range GetManagedLabels(node) {
+		alreadyManaged[name] = true
+		if _, desired := desiredLabels[name]; !desired {
+			managedLabels = append(managedLabels, name)
+		}
+	}
+
+	// init labels
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
 	nodeModified := false
-	for _, labels := range allLabels {
-		nodeModified = AddLabels(node, labels, log) || nodeModified
+	for name, value := range desiredLabels {
+		// only record labels we add or modify, so labels of other tools aren't removed when they aren't desired anymore
+		val, ok := node.Labels[name]
+		if alreadyManaged[name] || !ok || val != value {
+			managedLabels = append(managedLabels, name)
+		}
+		if !ok || val != value {
+			log.Info("Adding label to node", "node", node.Name, "labelName", name, "labelValue", value)
+			node.Labels[name] = value
+			nodeModified = true
+		}
 	}
-	return nodeModified
+	return SetManagedLabels(node, managedLabels) || nodeModified
 }
 
-// AddLabels adds the labels configured in the rules of the given Labels to the given node
-func AddLabels(node *v1.Node, labels v1beta1.Labels, log logr.Logger) bool {
-	if !labels.GetDeletionTimestamp().IsZero() {
-		return false
+// ForeignLabels returns the sorted names of the given desired labels, which are already set to another value
+// on the given node, but aren't managed labels. These labels were set by other tools.
+func ForeignLabels(node *v1.Node, desiredLabels map[string]string) []string {
+	managedLabels := map[string]bool{}
+	for _, name := range GetManagedLabels(node) {
+		managedLabels[name] = true
 	}
-	log.Info("Checking if labels need to be added to node", "node", node.Name, "label config", fmt.Sprintf("%+v", labels.Spec))
-	nodeModified := false
-	for _, nodeNamePattern := range labels.Spec.NodeNamePatterns {
-		pattern := fmt.Sprintf("%s%s%s", "^", nodeNamePattern, "$")
-		match, err := regexp.MatchString(pattern, node.Name)
-		if err != nil {
-			log.Error(err, "Invalid regular expression, moving on to next rule")
-			continue
+	var foreignLabels []string
+	for name, value := range desiredLabels {
+		if val, ok := node.Labels[name]; ok && val != value && !managedLabels[name] {
+			foreignLabels = append(foreignLabels, name)
 		}
-		if !match {
-			continue
+	}
+	sort.Strings(foreignLabels)
+	return foreignLabels
+}
+
+// DesiredLabels returns the labels of all given Labels matching the given node.
+// If multiple Labels set the same label to different values, the value of the Labels with the highest precedence wins.
+// Labels are evaluated in dependency order, against the labels of the node which aren't set by any Labels and the
+// labels desired by the Labels evaluated before, so derived labels follow their source labels in a single pass.
+func DesiredLabels(node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) map[string]string {
+	var liveLabels []v1beta1.Labels
+	for _, labels := range allLabels {
+		if labels.GetDeletionTimestamp().IsZero() {
+			liveLabels = append(liveLabels, labels)
+		}
+	}
+
+	nodeView := node.DeepCopy()
+	for _, name := range GetManagedLabels(node) {
+		for _, labels := range liveLabels {
+			if producesLabel(labels, name) {
+				delete(nodeView.Labels, name)
+				break
+			}
 		}
-		// init labels
-		if node.Labels == nil {
-			node.Labels = map[string]string{}
+	}
+	labelsByKey := map[types.NamespacedName]map[string]string{}
+	for _, labels := range SortByDependencies(liveLabels) {
+		log.Info("Checking if labels need to be added to node", "node", node.Name, "label config", fmt.Sprintf("%+v", labels.Spec))
+		nodeLabels := LabelsForNode(nodeView, labels, log)
+		labelsByKey[labelsKey(labels)] = nodeLabels
+		for name, value := range nodeLabels {
+			if nodeView.Labels == nil {
+				nodeView.Labels = map[string]string{}
+			}
+			if _, exists := nodeView.Labels[name]; !exists {
+				nodeView.Labels[name] = value
+			}
 		}
-		// we have a match, add labels!
-		for name, value := range labels.Spec.Labels {
-			if val, ok := node.Labels[name]; !ok || val != value {
-				log.Info("Adding label to node based on pattern", "node", node.Name, "pattern", nodeNamePattern, "labelName", name, "labelValue", value)
-				node.Labels[name] = value
-				nodeModified = true
+	}
+
+	desiredLabels := map[string]string{}
+	for _, labels := range SortByPrecedence(liveLabels) {
+		for name, value := range labelsByKey[labelsKey(labels)] {
+			if _, exists := desiredLabels[name]; !exists {
+				desiredLabels[name] = value
 			}
 		}
 	}
-	return nodeModified
+	return desiredLabels
 }
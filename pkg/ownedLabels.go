@@ -14,6 +14,11 @@ import (
 func IsOwnedLabel(nodeLabelDomainName string, ownedLabel v1beta1.OwnedLabels, log logr.Logger) bool {
 	log.Info("Check if we own label", "labelDomainName", nodeLabelDomainName, "OwnedLabel", ownedLabel.Name)
 
+	if ownedLabel.Spec.Domain == nil && ownedLabel.Spec.NamePattern == nil {
+		// only owns taints or annotations
+		return false
+	}
+
 	// split domainName
 	parts := strings.Split(nodeLabelDomainName, "/")
 	if len(parts) != 2 {
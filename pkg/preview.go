@@ -0,0 +1,201 @@
+package pkg
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// LiveLabels returns the given Labels which aren't in dry-run mode
+func LiveLabels(allLabels []v1beta1.Labels) []v1beta1.Labels {
+	var live []v1beta1.Labels
+	for _, labels := range allLabels {
+		if !labels.Spec.DryRun {
+			live = append(live, labels)
+		}
+	}
+	return live
+}
+
+// LiveOwnedLabels returns the given OwnedLabels which aren't in dry-run mode
+func LiveOwnedLabels(allOwnedLabels []v1beta1.OwnedLabels) []v1beta1.OwnedLabels {
+	var live []v1beta1.OwnedLabels
+	for _, ownedLabels := range allOwnedLabels {
+		if !ownedLabels.Spec.DryRun {
+			live = append(live, ownedLabels)
+		}
+	}
+	return live
+}
+
+// CoveringLabels returns the given Labels together with copies using their applied spec, for Labels whose applied
+// spec still covers labels and annotations on the given node:
+// - Labels in dry-run mode, so modifications of their spec don't remove labels from nodes. With the given global
+// dry-run flag all Labels are in dry-run mode.
+// - Labels whose removals on the given node aren't allowed yet, if the given limited flag is set because a removal
+// limit applies
+// The result must only be used for checking coverage.
+func CoveringLabels(nodeName string, allLabels []v1beta1.Labels, dryRun, limited bool) []v1beta1.Labels {
+	covering := make([]v1beta1.Labels, 0, len(allLabels))
+	for _, labels := range allLabels {
+		covering = append(covering, labels)
+		applied := AppliedLabels(labels)
+		if applied == nil || !hasRemovals(labels) {
+			continue
+		}
+		heldByDryRun := (dryRun || labels.Spec.DryRun) && labels.GetDeletionTimestamp().IsZero()
+		heldByLimit := limited && !IsRemovalApproved(&labels) && !contains(labels.Status.RemovableNodes, nodeName)
+		if heldByDryRun || heldByLimit {
+			covering = append(covering, *applied)
+		}
+	}
+	return covering
+}
+
+// AppliedLabels returns a copy of the given Labels with its applied spec, or nil if it wasn't applied yet.
+// The copy isn't marked for deletion, so it still covers the applied labels and annotations.
+func AppliedLabels(labels v1beta1.Labels) *v1beta1.Labels {
+	if labels.Status.AppliedSpec == nil {
+		return nil
+	}
+	applied := labels.DeepCopy()
+	applied.Spec = *labels.Status.AppliedSpec.DeepCopy()
+	applied.DeletionTimestamp = nil
+	return applied
+}
+
+// hasRemovals checks if the given Labels can remove labels or annotations of its applied spec, because it is
+// marked for deletion or its spec was modified
+func hasRemovals(labels v1beta1.Labels) bool {
+	return labels.Status.AppliedSpec != nil &&
+		(!labels.GetDeletionTimestamp().IsZero() || !reflect.DeepEqual(labels.Spec, *labels.Status.AppliedSpec))
+}
+
+// ManagedRemovals returns the sorted names of the managed labels and annotations of the applied spec of the given
+// Labels, which would be removed from the given node, because they aren't covered anymore by the current spec or any
+// other of the given Labels. With the given global dry-run flag all other Labels are in dry-run mode.
+func ManagedRemovals(node *v1.Node, labels v1beta1.Labels, allLabels []v1beta1.Labels, dryRun bool, log logr.Logger) ([]string, []string) {
+	if !hasRemovals(labels) {
+		return nil, nil
+	}
+	applied := AppliedLabels(labels)
+	var others []v1beta1.Labels
+	for _, other := range allLabels {
+		if other.Namespace != labels.Namespace || other.Name != labels.Name {
+			others = append(others, other)
+		}
+	}
+	covering := append(CoveringLabels(node.Name, others, dryRun, false), labels)
+
+	nodeCopy := node.DeepCopy()
+	RemoveManagedLabels(nodeCopy, covering, log)
+	RemoveManagedAnnotations(nodeCopy, covering, log)
+	var removedLabels, removedAnnotations []string
+	for name := range LabelsForNode(node, *applied, log) {
+		_, hadLabel := node.Labels[name]
+		if _, hasLabel := nodeCopy.Labels[name]; hadLabel && !hasLabel {
+			removedLabels = append(removedLabels, name)
+		}
+	}
+	sort.Strings(removedLabels)
+	for name := range AnnotationsForNode(node, *applied, log) {
+		_, hadAnnotation := node.Annotations[name]
+		if _, hasAnnotation := nodeCopy.Annotations[name]; hadAnnotation && !hasAnnotation {
+			removedAnnotations = append(removedAnnotations, name)
+		}
+	}
+	sort.Strings(removedAnnotations)
+	return removedLabels, removedAnnotations
+}
+
+// PreviewLabels returns the labels, annotations and taints of the given Labels, which would be added or modified
+// on the given node, and the managed labels and annotations of its applied spec, which would be removed,
+// or nil if there are no changes. Labels which are overridden by other Labels with higher precedence are skipped.
+// With the given global dry-run flag all other Labels are in dry-run mode as well.
+func PreviewLabels(node *v1.Node, labels v1beta1.Labels, allLabels []v1beta1.Labels, dryRun bool, log logr.Logger) *v1beta1.NodeLabelsPreview {
+	desiredLabels := DesiredLabels(node, allLabels, log)
+	preview := &v1beta1.NodeLabelsPreview{NodeName: node.Name}
+	for name, value := range LabelsForNode(node, labels, log) {
+		if desiredLabels[name] != value {
+			continue
+		}
+		val, ok := node.Labels[name]
+		switch {
+		case !ok:
+			if preview.WouldAdd == nil {
+				preview.WouldAdd = map[string]string{}
+			}
+			preview.WouldAdd[name] = value
+		case val != value:
+			if preview.WouldChange == nil {
+				preview.WouldChange = map[string]string{}
+			}
+			preview.WouldChange[name] = value
+		}
+	}
+	desiredAnnotations := DesiredAnnotations(node, allLabels, log)
+	for name, value := range AnnotationsForNode(node, labels, log) {
+		if desiredAnnotations[name] != value {
+			continue
+		}
+		if val, ok := node.Annotations[name]; !ok || val != value {
+			if preview.WouldAddAnnotations == nil {
+				preview.WouldAddAnnotations = map[string]string{}
+			}
+			preview.WouldAddAnnotations[name] = value
+		}
+	}
+	desiredTaints := DesiredTaints(node, allLabels, log)
+	for _, taint := range TaintsForNode(node, labels, log) {
+		if desired := findTaint(desiredTaints, taint); desired == nil || desired.Value != taint.Value {
+			continue
+		}
+		if existing := findTaint(node.Spec.Taints, taint); existing == nil || existing.Value != taint.Value {
+			preview.WouldAddTaints = append(preview.WouldAddTaints, taint)
+		}
+	}
+	if dryRun || labels.Spec.DryRun {
+		preview.WouldRemove, preview.WouldRemoveAnnotations = ManagedRemovals(node, labels, allLabels, dryRun, log)
+	}
+	if len(preview.WouldAdd) == 0 && len(preview.WouldChange) == 0 && len(preview.WouldAddAnnotations) == 0 && len(preview.WouldAddTaints) == 0 &&
+		len(preview.WouldRemove) == 0 && len(preview.WouldRemoveAnnotations) == 0 {
+		return nil
+	}
+	return preview
+}
+
+// PreviewOwnedLabels returns the labels, annotations and taints owned by the given OwnedLabels, which would be removed from the
+// given node, or nil if there are no changes
+func PreviewOwnedLabels(node *v1.Node, ownedLabels v1beta1.OwnedLabels, allLabels []v1beta1.Labels, log logr.Logger) *v1beta1.NodeLabelsPreview {
+	nodeCopy := node.DeepCopy()
+	removedLabels := RemoveOwnedLabels(nodeCopy, []v1beta1.OwnedLabels{ownedLabels}, allLabels, log)
+	removedAnnotations := RemoveOwnedAnnotations(nodeCopy, []v1beta1.OwnedLabels{ownedLabels}, allLabels, log)
+	removedTaints := RemoveOwnedTaints(nodeCopy, []v1beta1.OwnedLabels{ownedLabels}, allLabels, log)
+	if !removedLabels && !removedAnnotations && !removedTaints {
+		return nil
+	}
+	preview := &v1beta1.NodeLabelsPreview{NodeName: node.Name}
+	for name := range node.Labels {
+		if _, ok := nodeCopy.Labels[name]; !ok {
+			preview.WouldRemove = append(preview.WouldRemove, name)
+		}
+	}
+	sort.Strings(preview.WouldRemove)
+	for name := range node.Annotations {
+		if _, ok := nodeCopy.Annotations[name]; !ok {
+			preview.WouldRemoveAnnotations = append(preview.WouldRemoveAnnotations, name)
+		}
+	}
+	sort.Strings(preview.WouldRemoveAnnotations)
+	for _, taint := range node.Spec.Taints {
+		if findTaint(nodeCopy.Spec.Taints, taint) == nil {
+			preview.WouldRemoveTaints = append(preview.WouldRemoveTaints, taint)
+		}
+	}
+	return preview
+}
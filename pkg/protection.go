@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"sort"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// ProtectedLabelViolation describes a node update, which removes a label or sets it to another value than the value
+// defined by the Labels which owns it
+type ProtectedLabelViolation struct {
+	LabelName string
+	Value     string
+	Labels    types.NamespacedName
+}
+
+// ProtectedLabelViolations returns all labels, which are added, modified or removed by the update from the given
+// old node to the given node, and which contradict the rules of the given Labels.
+// Each label is owned by the Labels with the highest precedence covering it.
+func ProtectedLabelViolations(oldNode, node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) []ProtectedLabelViolation {
+	var violations []ProtectedLabelViolation
+	seen := map[string]bool{}
+	for _, labels := range SortByPrecedence(allLabels) {
+		if !labels.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		for name, value := range LabelsForNode(node, labels, log) {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			oldValue, hadLabel := oldNode.Labels[name]
+			newValue, hasLabel := node.Labels[name]
+			if hadLabel == hasLabel && oldValue == newValue {
+				// unchanged
+				continue
+			}
+			if hasLabel && newValue == value {
+				continue
+			}
+			violations = append(violations, ProtectedLabelViolation{
+				LabelName: name,
+				Value:     value,
+				Labels:    types.NamespacedName{Namespace: labels.Namespace, Name: labels.Name},
+			})
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].LabelName < violations[j].LabelName
+	})
+	return violations
+}
+
+// IsProtectionBypassed checks if the break-glass annotation is set on the given node
+func IsProtectionBypassed(node *v1.Node) bool {
+	return node.Annotations[v1beta1.AnnotationAllowEdits] == "true"
+}
@@ -0,0 +1,138 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// BlockedOwnedLabels returns the OwnedLabels which would remove owned labels from more nodes than allowed,
+// with a message describing the exceeded limit.
+// Every OwnedLabels is checked against its own limit first. The remaining OwnedLabels are checked against the given
+// global limit together, and all of them are blocked if it is exceeded. OwnedLabels with approved removals are
+// never blocked. Labels cover owned labels like for the NodeReconciler, with the given global dry-run flag.
+func BlockedOwnedLabels(nodes []v1.Node, allOwnedLabels []v1beta1.OwnedLabels, allLabels []v1beta1.Labels, dryRun bool, globalMaxRemovals *intstr.IntOrString, log logr.Logger) map[types.NamespacedName]string {
+	blocked := map[types.NamespacedName]string{}
+	var candidates []types.NamespacedName
+	affectedNodes := map[string]bool{}
+	for _, ownedLabels := range allOwnedLabels {
+		if !ownedLabels.GetDeletionTimestamp().IsZero() || IsRemovalApproved(&ownedLabels) {
+			continue
+		}
+		key := types.NamespacedName{Namespace: ownedLabels.Namespace, Name: ownedLabels.Name}
+
+		var nodeNames []string
+		for i := range nodes {
+			coveringLabels := CoveringLabels(nodes[i].Name, allLabels, dryRun, globalMaxRemovals != nil)
+			if PreviewOwnedLabels(&nodes[i], ownedLabels, coveringLabels, log) != nil {
+				nodeNames = append(nodeNames, nodes[i].Name)
+			}
+		}
+		if len(nodeNames) == 0 {
+			continue
+		}
+
+		if exceeded, message := exceedsMaxRemovals(len(nodeNames), len(nodes), ownedLabels.Spec.MaxRemovals, "", "owned"); exceeded {
+			log.Info("Blocking removal of owned labels", "ownedLabels", key, "reason", message)
+			blocked[key] = message
+			continue
+		}
+		candidates = append(candidates, key)
+		for _, nodeName := range nodeNames {
+			affectedNodes[nodeName] = true
+		}
+	}
+
+	if exceeded, message := exceedsMaxRemovals(len(affectedNodes), len(nodes), globalMaxRemovals, "global ", "owned"); exceeded {
+		for _, key := range candidates {
+			log.Info("Blocking removal of owned labels", "ownedLabels", key, "reason", message)
+			blocked[key] = message
+		}
+	}
+	return blocked
+}
+
+// RemovableOwnedLabels returns the given OwnedLabels which can remove owned labels from the given node, because no
+// removal limit applies to them, their removals were approved, or the node is one of their removable nodes.
+// The removable nodes are published in the status by the OwnedLabelsReconciler, so OwnedLabels whose removals
+// weren't checked against the limits yet don't remove anything.
+func RemovableOwnedLabels(nodeName string, allOwnedLabels []v1beta1.OwnedLabels, globalMaxRemovals *intstr.IntOrString) []v1beta1.OwnedLabels {
+	var removable []v1beta1.OwnedLabels
+	for _, ownedLabels := range allOwnedLabels {
+		if (ownedLabels.Spec.MaxRemovals == nil && globalMaxRemovals == nil) || IsRemovalApproved(&ownedLabels) ||
+			contains(ownedLabels.Status.RemovableNodes, nodeName) {
+			removable = append(removable, ownedLabels)
+		}
+	}
+	return removable
+}
+
+// RemoveUncovered removes the managed and owned labels, annotations and taints from the given node, which aren't
+// covered by the given Labels anymore, except the given kept labels, and returns true if the node was modified.
+// Owned labels, annotations and taints are only removed by the given OwnedLabels which aren't blocked by their
+// removal limit on the node, see RemovableOwnedLabels.
+func RemoveUncovered(node *v1.Node, allLabels []v1beta1.Labels, allOwnedLabels []v1beta1.OwnedLabels, globalMaxRemovals *intstr.IntOrString, keep []string, log logr.Logger) bool {
+	removableOwnedLabels := RemovableOwnedLabels(node.Name, LiveOwnedLabels(allOwnedLabels), globalMaxRemovals)
+	coveringLabels := CoveringLabels(node.Name, allLabels, false, globalMaxRemovals != nil)
+	nodeModified := RemoveManagedLabels(node, coveringLabels, log)
+	nodeModified = RemoveOwnedLabelsExcept(node, removableOwnedLabels, coveringLabels, keep, log) || nodeModified
+	nodeModified = RemoveManagedAnnotations(node, coveringLabels, log) || nodeModified
+	nodeModified = RemoveOwnedAnnotations(node, removableOwnedLabels, coveringLabels, log) || nodeModified
+	return RemoveOwnedTaints(node, removableOwnedLabels, coveringLabels, log) || nodeModified
+}
+
+// BlockedLabels returns the sorted names of the given nodes, which would lose managed labels or annotations of the
+// applied spec of the given Labels, and a message describing the exceeded limit if these are more nodes than allowed
+// by the given global limit. Labels with approved removals are never blocked. Nodes with the allow-edits annotation
+// are skipped, since they aren't modified by the NodeReconciler.
+func BlockedLabels(nodes []v1.Node, labels v1beta1.Labels, allLabels []v1beta1.Labels, globalMaxRemovals *intstr.IntOrString, log logr.Logger) ([]string, string) {
+	var nodeNames []string
+	for i := range nodes {
+		if IsProtectionBypassed(&nodes[i]) {
+			// not modified by the NodeReconciler
+			continue
+		}
+		if removedLabels, removedAnnotations := ManagedRemovals(&nodes[i], labels, allLabels, false, log); len(removedLabels) > 0 || len(removedAnnotations) > 0 {
+			nodeNames = append(nodeNames, nodes[i].Name)
+		}
+	}
+	sort.Strings(nodeNames)
+	if IsRemovalApproved(&labels) {
+		return nodeNames, ""
+	}
+	if exceeded, message := exceedsMaxRemovals(len(nodeNames), len(nodes), globalMaxRemovals, "global ", "managed"); exceeded {
+		log.Info("Blocking removal of managed labels", "labels", labels.Name, "reason", message)
+		return nodeNames, message
+	}
+	return nodeNames, ""
+}
+
+// IsRemovalApproved checks if the given Labels or OwnedLabels has approved removals exceeding the removal limit
+func IsRemovalApproved(obj metav1.Object) bool {
+	return obj.GetAnnotations()[v1beta1.AnnotationApproveRemovals] == "true"
+}
+
+// exceedsMaxRemovals checks if the given number of nodes losing labels of the given kind exceeds the given limit
+func exceedsMaxRemovals(affectedNodes, totalNodes int, maxRemovals *intstr.IntOrString, limitType, labelKind string) (bool, string) {
+	if maxRemovals == nil || affectedNodes == 0 {
+		return false, ""
+	}
+	limit, err := intstr.GetValueFromIntOrPercent(maxRemovals, totalNodes, false)
+	if err != nil {
+		// fail closed
+		return true, fmt.Sprintf("invalid %slimit %q: %v", limitType, maxRemovals.String(), err)
+	}
+	if affectedNodes <= limit {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%d of %d nodes would lose %s labels, which exceeds the %slimit of %s",
+		affectedNodes, totalNodes, labelKind, limitType, maxRemovals.String())
+}
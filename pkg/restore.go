@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"sort"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// RestoreManagedLabels restores the managed labels of the given old node on the given updated node, if they are
+// still desired by the given Labels but were removed or modified by the update. It returns the sorted names of the
+// restored labels.
+func RestoreManagedLabels(oldNode, node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) []string {
+	desiredLabels := DesiredLabels(node, allLabels, log)
+	var restored []string
+	for _, name := range GetManagedLabels(oldNode) {
+		value, desired := desiredLabels[name]
+		if !desired {
+			continue
+		}
+		if val, ok := node.Labels[name]; ok && val == value {
+			continue
+		}
+		log.Info("Restoring managed label", "node", node.Name, "labelName", name, "labelValue", value)
+		if node.Labels == nil {
+			node.Labels = map[string]string{}
+		}
+		node.Labels[name] = value
+		restored = append(restored, name)
+	}
+	sort.Strings(restored)
+	return restored
+}
+
+// RestoreManagedAnnotations restores the managed annotations of the given old node on the given updated node, if they
+// are still desired by the given Labels but were removed or modified by the update. Names of still desired labels and
+// annotations, which were removed from the annotations recording them, are restored as well. It returns the sorted
+// names of the restored annotations.
+func RestoreManagedAnnotations(oldNode, node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) []string {
+	desiredAnnotations := DesiredAnnotations(node, allLabels, log)
+	var restored []string
+	for _, name := range GetManagedAnnotations(oldNode) {
+		value, desired := desiredAnnotations[name]
+		if !desired {
+			continue
+		}
+		if val, ok := node.Annotations[name]; ok && val == value {
+			continue
+		}
+		log.Info("Restoring managed annotation", "node", node.Name, "annotationName", name)
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[name] = value
+		restored = append(restored, name)
+	}
+	if restoreManagedKeys(oldNode, node, v1beta1.AnnotationManagedLabels, DesiredLabels(node, allLabels, log), log) {
+		restored = append(restored, v1beta1.AnnotationManagedLabels)
+	}
+	if restoreManagedKeys(oldNode, node, v1beta1.AnnotationManagedAnnotations, desiredAnnotations, log) {
+		restored = append(restored, v1beta1.AnnotationManagedAnnotations)
+	}
+	sort.Strings(restored)
+	return restored
+}
+
+// restoreManagedKeys adds the names recorded in the given annotation of the given old node to the same annotation of
+// the given updated node, if they are still in the given desired labels or annotations, and returns true if the node
+// was modified. The annotation isn't restored as a whole, since names which aren't desired anymore are removed by
+// the NodeReconciler, and names which were added by the update, e.g. by the NodeReconciler itself, must be kept.
+func restoreManagedKeys(oldNode, node *v1.Node, annotationName string, desired map[string]string, log logr.Logger) bool {
+	managed := getManagedKeys(node, annotationName)
+	recorded := map[string]bool{}
+	for _, name := range managed {
+		recorded[name] = true
+	}
+	nodeModified := false
+	for _, name := range getManagedKeys(oldNode, annotationName) {
+		if _, ok := desired[name]; !ok || recorded[name] {
+			continue
+		}
+		log.Info("Restoring managed key", "node", node.Name, "annotationName", annotationName, "key", name)
+		managed = append(managed, name)
+		nodeModified = true
+	}
+	if !nodeModified {
+		return false
+	}
+	return setManagedKeys(node, annotationName, managed)
+}
@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// scheduleWindow returns the start and the end of the time window in which the given Labels is active, as defined by
+// notBefore, notAfter and ttl. Zero times mean that the window is unbounded.
+func scheduleWindow(labels v1beta1.Labels) (start, end time.Time) {
+	if labels.Spec.NotBefore != nil {
+		start = labels.Spec.NotBefore.Time
+	}
+	if labels.Spec.NotAfter != nil {
+		end = labels.Spec.NotAfter.Time
+	}
+	if labels.Spec.TTL != nil {
+		ttlStart := start
+		if ttlStart.IsZero() {
+			ttlStart = labels.CreationTimestamp.Time
+		}
+		if !ttlStart.IsZero() {
+			if ttlEnd := ttlStart.Add(labels.Spec.TTL.Duration); end.IsZero() || ttlEnd.Before(end) {
+				end = ttlEnd
+			}
+		}
+	}
+	return start, end
+}
+
+// IsActive checks if the given time is within the time window of the given Labels
+func IsActive(labels v1beta1.Labels, now time.Time) bool {
+	start, end := scheduleWindow(labels)
+	return (start.IsZero() || !now.Before(start)) && (end.IsZero() || now.Before(end))
+}
+
+// IsExpired checks if the time window of the given Labels is over at the given time
+func IsExpired(labels v1beta1.Labels, now time.Time) bool {
+	_, end := scheduleWindow(labels)
+	return !end.IsZero() && !now.Before(end)
+}
+
+// NextTransition returns the next time after the given time, at which the given Labels is activated or expires.
+// It returns a zero time if there is no further transition.
+func NextTransition(labels v1beta1.Labels, now time.Time) time.Time {
+	start, end := scheduleWindow(labels)
+	switch {
+	case !start.IsZero() && now.Before(start):
+		return start
+	case !end.IsZero() && now.Before(end):
+		return end
+	default:
+		return time.Time{}
+	}
+}
+
+// NextScheduleTransition returns the time after which any of the given Labels is activated or expires.
+// It returns 0 if none of them has a further transition.
+func NextScheduleTransition(allLabels []v1beta1.Labels, now time.Time) time.Duration {
+	var next time.Duration
+	for _, labels := range allLabels {
+		transition := NextTransition(labels, now)
+		if transition.IsZero() {
+			continue
+		}
+		if remaining := transition.Sub(now); next == 0 || remaining < next {
+			next = remaining
+		}
+	}
+	return next
+}
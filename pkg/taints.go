@@ -0,0 +1,157 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// TaintsForNode returns the taints of the given Labels for the given node, or nil if the node doesn't match
+func TaintsForNode(node *v1.Node, labels v1beta1.Labels, log logr.Logger) []v1.Taint {
+	if len(labels.Spec.Taints) == 0 || !MatchesNode(node, labels, log) {
+		return nil
+	}
+	return labels.Spec.Taints
+}
+
+// DesiredTaints returns the taints of all given Labels matching the given node.
+// If multiple Labels set a taint with the same key and effect, the taint of the Labels with the highest precedence wins.
+func DesiredTaints(node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) []v1.Taint {
+	var desiredTaints []v1.Taint
+	for _, labels := range SortByPrecedence(allLabels) {
+		if !labels.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		for _, taint := range TaintsForNode(node, labels, log) {
+			if findTaint(desiredTaints, taint) == nil {
+				desiredTaints = append(desiredTaints, taint)
+			}
+		}
+	}
+	return desiredTaints
+}
+
+// AddAllTaints adds the taints configured in the rules of the given Labels to the given node
+// If multiple Labels set a taint with the same key and effect, the Labels with the highest precedence wins.
+func AddAllTaints(node *v1.Node, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	return ApplyTaints(node, DesiredTaints(node, allLabels, log), log)
+}
+
+// ApplyTaints adds the given desired taints to the given node, or updates their values
+func ApplyTaints(node *v1.Node, desiredTaints []v1.Taint, log logr.Logger) bool {
+	nodeModified := false
+	for _, taint := range desiredTaints {
+		existing := findTaint(node.Spec.Taints, taint)
+		if existing == nil {
+			log.Info("Adding taint to node", "node", node.Name, "taint", taint.ToString())
+			node.Spec.Taints = append(node.Spec.Taints, taint)
+			nodeModified = true
+			continue
+		}
+		if existing.Value != taint.Value {
+			log.Info("Updating taint on node", "node", node.Name, "taint", taint.ToString())
+			existing.Value = taint.Value
+			nodeModified = true
+		}
+	}
+	return nodeModified
+}
+
+// RemoveOwnedTaints removes all uncovered owned taints from the node and return true if the node was modified
+func RemoveOwnedTaints(node *v1.Node, allOwnedLabels []v1beta1.OwnedLabels, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	log.Info("Checking owned taints", "node", node.Name)
+	allOwnedLabels = OwnedLabelsForNode(node, allOwnedLabels, log)
+	var taints []v1.Taint
+	for _, taint := range node.Spec.Taints {
+		if isOwnedTaintByAny(taint, allOwnedLabels, log) && !IsTaintCoveredByAll(node, taint, allLabels, log) {
+			log.Info("Deleting uncovered owned taint", "node", node.Name, "taint", taint.ToString())
+			continue
+		}
+		taints = append(taints, taint)
+	}
+	if len(taints) == len(node.Spec.Taints) {
+		return false
+	}
+	node.Spec.Taints = taints
+	return true
+}
+
+// IsTaintCoveredByAll checks if a taint with the key and effect of the given taint is covered by the rules of the
+// given allLabels for the given node
+func IsTaintCoveredByAll(node *v1.Node, taint v1.Taint, allLabels []v1beta1.Labels, log logr.Logger) bool {
+	for _, labels := range allLabels {
+		if !labels.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if findTaint(TaintsForNode(node, labels, log), taint) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOwnedTaint checks if the given taint matches the taint key pattern of the given OwnedLabels
+func IsOwnedTaint(taint v1.Taint, ownedLabels v1beta1.OwnedLabels, log logr.Logger) bool {
+	if ownedLabels.Spec.TaintKeyPattern == nil {
+		return false
+	}
+	pattern := fmt.Sprintf("%s%s%s", "^", *ownedLabels.Spec.TaintKeyPattern, "$")
+	match, err := regexp.MatchString(pattern, taint.Key)
+	if err != nil {
+		log.Error(err, "Invalid regular expression, moving on", "pattern", ownedLabels.Spec.TaintKeyPattern)
+		return false
+	}
+	return match
+}
+
+// HasOwnedKeys checks if the given node has any label, taint or annotation owned by the given OwnedLabels
+func HasOwnedKeys(node *v1.Node, ownedLabels v1beta1.OwnedLabels, log logr.Logger) bool {
+	if len(OwnedLabelsForNode(node, []v1beta1.OwnedLabels{ownedLabels}, log)) == 0 {
+		return false
+	}
+	for labelDomainName := range node.Labels {
+		if IsOwnedLabel(labelDomainName, ownedLabels, log) {
+			return true
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		if IsOwnedTaint(taint, ownedLabels, log) {
+			return true
+		}
+	}
+	for name := range node.Annotations {
+		if IsOwnedAnnotation(name, ownedLabels, log) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTaint checks if the given node has a taint with the key and effect of the given taint
+func HasTaint(node *v1.Node, taint v1.Taint) bool {
+	return findTaint(node.Spec.Taints, taint) != nil
+}
+
+func isOwnedTaintByAny(taint v1.Taint, allOwnedLabels []v1beta1.OwnedLabels, log logr.Logger) bool {
+	for _, ownedLabels := range allOwnedLabels {
+		if IsOwnedTaint(taint, ownedLabels, log) {
+			return true
+		}
+	}
+	return false
+}
+
+// findTaint returns the taint with the key and effect of the given taint, or nil if there is none
+func findTaint(taints []v1.Taint, taint v1.Taint) *v1.Taint {
+	for i := range taints {
+		if taints[i].MatchTaint(&taint) {
+			return &taints[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// emptyPattern is used for expanding templates when no node name pattern is available,
+// it results in all capture group references being replaced with an empty string
+var emptyPattern = regexp.MustCompile("^$")
+
+// LabelsForNode returns the labels of the given Labels for the given node, with all templates expanded, the
+// distributed labels with the value of the node, the label of the matching CIDR of the node address selector, and
+// the inventory labels of the node.
+// It returns nil if the node doesn't match the Labels.
+// Invalid labels are skipped.
+func LabelsForNode(node *v1.Node, labels v1beta1.Labels, log logr.Logger) map[string]string {
+	result := expandTemplates(node, labels, labels.Spec.Labels, validateLabel, log)
+	if result == nil {
+		return nil
+	}
+	for name, value := range distributedLabelsForNode(node, labels) {
+		if err := validateLabel(name, value); err != nil {
+			log.Error(err, "Invalid distributed label, skipping it", "node", node.Name)
+			continue
+		}
+		result[name] = value
+	}
+	if name, value, ok := addressLabelForNode(node, labels, log); ok {
+		if err := validateLabel(name, value); err != nil {
+			log.Error(err, "Invalid address label, skipping it", "node", node.Name)
+		} else {
+			result[name] = value
+		}
+	}
+	for name, value := range inventoryLabelsForNode(node, labels) {
+		// explicit and distributed labels take precedence
+		if _, exists := result[name]; !exists {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+// AnnotationsForNode returns the annotations of the given Labels for the given node, with all templates expanded.
+// It returns nil if the node doesn't match the Labels.
+// Invalid annotations are skipped.
+func AnnotationsForNode(node *v1.Node, labels v1beta1.Labels, log logr.Logger) map[string]string {
+	return expandTemplates(node, labels, labels.Spec.Annotations, validateAnnotation, log)
+}
+
+// expandTemplates returns the given templates with all templates expanded for the given node, if it matches the given
+// Labels. Results which aren't valid according to the given validate func are skipped.
+func expandTemplates(node *v1.Node, labels v1beta1.Labels, templates map[string]string, validate func(name, value string) error, log logr.Logger) map[string]string {
+	if !MatchesNode(node, labels, log) {
+		return nil
+	}
+
+	re, submatches := matchNodeName(node.Name, labels, log)
+	if re == nil {
+		re, submatches = emptyPattern, emptyPattern.FindStringSubmatchIndex("")
+	}
+
+	result := make(map[string]string, len(templates))
+	for name, value := range templates {
+		expandedName := expandTemplate(name, re, node.Name, submatches)
+		expandedValue := expandTemplate(value, re, node.Name, submatches)
+		if err := validate(expandedName, expandedValue); err != nil {
+			log.Error(err, "Invalid result after expanding templates, skipping it", "node", node.Name, "name", name, "value", value)
+			continue
+		}
+		result[expandedName] = expandedValue
+	}
+	return result
+}
+
+// expandTemplate replaces references to capture groups of the given regex, like ${1} or ${name}, with their values
+func expandTemplate(template string, re *regexp.Regexp, nodeName string, submatches []int) string {
+	if !strings.Contains(template, "$") {
+		return template
+	}
+	return string(re.ExpandString(nil, template, nodeName, submatches))
+}
+
+// addressLabelForNode returns the label of the node address selector of the given Labels, with the value of the CIDR
+// matching the given node
+func addressLabelForNode(node *v1.Node, labels v1beta1.Labels, log logr.Logger) (string, string, bool) {
+	selector := labels.Spec.NodeAddressSelector
+	if selector == nil || selector.LabelName == "" {
+		return "", "", false
+	}
+	value, ok := selector.Values[matchNodeAddress(node, selector, log)]
+	if !ok {
+		return "", "", false
+	}
+	return selector.LabelName, value, true
+}
+
+func validateLabel(name, value string) error {
+	if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+		return fmt.Errorf("invalid label name %q: %s", name, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return fmt.Errorf("invalid label value %q: %s", value, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func validateAnnotation(name, _ string) error {
+	if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+		return fmt.Errorf("invalid annotation name %q: %s", name, strings.Join(errs, "; "))
+	}
+	if isReservedAnnotation(name) {
+		return fmt.Errorf("invalid annotation name %q: reserved for the operator", name)
+	}
+	return nil
+}
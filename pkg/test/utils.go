@@ -25,6 +25,9 @@ const (
 	DummyNode1Name = "first-node"
 	DummyNode2Name = "second-node"
 
+	// DenyManagedEditsLabel selects the nodes whose managed labels can't be modified in the webhook tests
+	DenyManagedEditsLabel = "test.openshift.io/deny-managed-edits"
+
 	LabelDomain        = "test.openshift.io"
 	LabelName          = "foo1"
 	LabelValue         = "bar1"
@@ -38,6 +41,8 @@ var (
 	Label         = map[string]string{LabelDomainName: LabelValue}
 	LabelNewValue = map[string]string{LabelDomainName: LabelValueNew}
 	LabelNewName  = map[string]string{LabelDomainNameNew: LabelValue}
+	Annotation    = map[string]string{LabelDomainName: LabelValue}
+	Taint         = v1.Taint{Key: LabelDomainName, Value: LabelValue, Effect: v1.TaintEffectNoSchedule}
 
 	K8sClient *client.Client
 	IsE2etest = false
@@ -60,6 +65,14 @@ func GetLabels(nodeNamePattern string) *v1beta1.Labels {
 	}
 }
 
+func GetLabelsWithSelector(nodeSelector *metav1.LabelSelector) *v1beta1.Labels {
+	labels := GetLabels("")
+	labels.Spec.NodeNamePatterns = nil
+	labels.Spec.NodeSelector = nodeSelector
+	labels.Spec.Labels = LabelNewName
+	return labels
+}
+
 func GetOwnedLabels() *v1beta1.OwnedLabels {
 	return &v1beta1.OwnedLabels{
 		TypeMeta: metav1.TypeMeta{
@@ -76,6 +89,23 @@ func GetOwnedLabels() *v1beta1.OwnedLabels {
 	}
 }
 
+func GetLabelMigration(sourcePattern, target string) *v1beta1.LabelMigration {
+	return &v1beta1.LabelMigration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "LabelMigration",
+			APIVersion: "node-labels.openshift.io/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "test-labelmigration-",
+			Namespace:    "default",
+		},
+		Spec: v1beta1.LabelMigrationSpec{
+			SourcePattern: sourcePattern,
+			Target:        target,
+		},
+	}
+}
+
 func FindWorkerNodes() []*v1.Node {
 
 	var nodes []*v1.Node
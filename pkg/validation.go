@@ -0,0 +1,394 @@
+package pkg
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift-kni/node-label-operator/api/v1beta1"
+)
+
+// templateReference matches references to capture groups in templates, like $1, ${1}, $name or ${name}
+var templateReference = regexp.MustCompile(`\$(\{[^}]*\}|[A-Za-z0-9_]+)`)
+
+// ValidateLabels checks if the given Labels is valid
+func ValidateLabels(labels v1beta1.Labels) error {
+	var errs []error
+	if err := ValidateNodeSelection(labels); err != nil {
+		errs = append(errs, err)
+	}
+	hasAddressLabel := labels.Spec.NodeAddressSelector != nil && labels.Spec.NodeAddressSelector.LabelName != ""
+	if len(labels.Spec.Labels) == 0 && len(labels.Spec.Distributions) == 0 && labels.Spec.Inventory == nil &&
+		!hasAddressLabel && len(labels.Spec.Annotations) == 0 && len(labels.Spec.Taints) == 0 {
+		errs = append(errs, fmt.Errorf("at least one of labels, distributions, inventory, a node address label, annotations and taints must be set"))
+	}
+	for name, value := range labels.Spec.Labels {
+		if err := validateLabelTemplate(name, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validateDistributions(labels); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateInventory(labels.Spec.Inventory); err != nil {
+		errs = append(errs, err)
+	}
+	if labels.Spec.Inventory != nil && !hasNodeSelection(labels) {
+		errs = append(errs, fmt.Errorf("invalid inventory: node selection criteria must be set, inventory rows are only matched to the matching nodes"))
+	}
+	for name, value := range labels.Spec.Annotations {
+		if err := validateAnnotationTemplate(name, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, taint := range labels.Spec.Taints {
+		if err := validateTaint(taint); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validateSchedule(labels); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateSchedule checks if the time window of the given Labels is valid
+func validateSchedule(labels v1beta1.Labels) error {
+	if labels.Spec.NotBefore != nil && labels.Spec.NotAfter != nil && !labels.Spec.NotAfter.After(labels.Spec.NotBefore.Time) {
+		return fmt.Errorf("invalid schedule: notAfter %s must be after notBefore %s",
+			labels.Spec.NotAfter.UTC().Format(time.RFC3339), labels.Spec.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if labels.Spec.TTL != nil && labels.Spec.TTL.Duration <= 0 {
+		return fmt.Errorf("invalid schedule: ttl %s must be positive", labels.Spec.TTL.Duration)
+	}
+	return nil
+}
+
+// ValidateNodeSelection checks if the node selection criteria of the given Labels are valid
+func ValidateNodeSelection(labels v1beta1.Labels) error {
+	var errs []error
+	for _, nodeNamePattern := range labels.Spec.NodeNamePatterns {
+		if _, err := regexp.Compile(fmt.Sprintf("%s%s%s", "^", nodeNamePattern, "$")); err != nil {
+			errs = append(errs, fmt.Errorf("invalid node name pattern %q: %v", nodeNamePattern, err))
+		}
+	}
+	if labels.Spec.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(labels.Spec.NodeSelector); err != nil {
+			errs = append(errs, fmt.Errorf("invalid node selector: %v", err))
+		}
+	}
+	for _, pattern := range labels.Spec.ExcludeNodeNamePatterns {
+		if _, err := compileNodeNamePattern(nil, pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid exclude node name pattern %q: %v", pattern, err))
+		}
+	}
+	if labels.Spec.ExcludeNodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(labels.Spec.ExcludeNodeSelector); err != nil {
+			errs = append(errs, fmt.Errorf("invalid exclude node selector: %v", err))
+		}
+	}
+	for _, term := range labels.Spec.NodeFieldSelectorTerms {
+		for _, requirement := range term.MatchFields {
+			if err := validateNodeFieldRequirement(requirement); err != nil {
+				errs = append(errs, fmt.Errorf("invalid node field selector term: %v", err))
+			}
+		}
+	}
+	if err := validateNodeAddressSelector(labels.Spec.NodeAddressSelector, labels.Spec.Labels); err != nil {
+		errs = append(errs, err)
+	}
+	for _, requirement := range labels.Spec.NodeResourceRequirements {
+		if err := validateNodeResourceRequirement(requirement); err != nil {
+			errs = append(errs, fmt.Errorf("invalid node resource requirement: %v", err))
+		}
+	}
+	for _, requirement := range labels.Spec.NodeConditionRequirements {
+		if err := validateNodeConditionRequirement(requirement); err != nil {
+			errs = append(errs, fmt.Errorf("invalid node condition requirement: %v", err))
+		}
+	}
+	if labels.Spec.MatchExpression != "" {
+		if _, err := CompileMatchExpression(labels.Spec.MatchExpression); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validateAssignment(labels.Spec.Assignment); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateNodeAddressSelector checks if the given node address selector is valid, and that its label doesn't collide
+// with the given static labels
+func validateNodeAddressSelector(selector *v1beta1.NodeAddressSelector, labels map[string]string) error {
+	if selector == nil {
+		return nil
+	}
+	var errs []error
+	if selector.Type != v1.NodeInternalIP && selector.Type != v1.NodeExternalIP {
+		errs = append(errs, fmt.Errorf("invalid node address selector: unsupported address type %q", selector.Type))
+	}
+	if len(selector.CIDRs) == 0 {
+		errs = append(errs, fmt.Errorf("invalid node address selector: at least one CIDR must be set"))
+	}
+	for _, cidr := range selector.CIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid node address selector CIDR %q: %v", cidr, err))
+		}
+	}
+	if selector.LabelName == "" {
+		if len(selector.Values) > 0 {
+			errs = append(errs, fmt.Errorf("invalid node address selector: values require a labelName"))
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+	if len(selector.Values) == 0 {
+		errs = append(errs, fmt.Errorf("invalid node address selector: labelName requires values"))
+	}
+	if !strings.Contains(selector.LabelName, "/") {
+		errs = append(errs, fmt.Errorf("invalid label name %q: must be in domain/name format", selector.LabelName))
+	}
+	if _, exists := labels[selector.LabelName]; exists {
+		errs = append(errs, fmt.Errorf("invalid node address selector: label %q is also set in labels", selector.LabelName))
+	}
+	for cidr, value := range selector.Values {
+		if !contains(selector.CIDRs, cidr) {
+			errs = append(errs, fmt.Errorf("invalid node address selector: value for unknown CIDR %q", cidr))
+		}
+		if err := validateLabel(selector.LabelName, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateNodeConditionRequirement checks if the given node condition requirement is valid
+func validateNodeConditionRequirement(requirement v1beta1.NodeConditionRequirement) error {
+	if requirement.Type == "" {
+		return fmt.Errorf("missing condition type")
+	}
+	switch requirement.Status {
+	case v1.ConditionTrue, v1.ConditionFalse, v1.ConditionUnknown:
+	default:
+		return fmt.Errorf("unsupported status %q for condition %q", requirement.Status, requirement.Type)
+	}
+	if requirement.MinDuration != nil && requirement.MinDuration.Duration < 0 {
+		return fmt.Errorf("negative minDuration for condition %q", requirement.Type)
+	}
+	return nil
+}
+
+// validateAssignment checks if the given node assignment is valid
+func validateAssignment(assignment *v1beta1.NodeAssignment) error {
+	if assignment == nil {
+		return nil
+	}
+	if (assignment.MaxNodes == nil) == (assignment.Percentage == nil) {
+		return fmt.Errorf("invalid assignment: exactly one of maxNodes and percentage must be set")
+	}
+	if assignment.MaxNodes != nil && *assignment.MaxNodes < 0 {
+		return fmt.Errorf("invalid assignment: maxNodes must not be negative")
+	}
+	if assignment.Percentage != nil && (*assignment.Percentage < 0 || *assignment.Percentage > 100) {
+		return fmt.Errorf("invalid assignment: percentage must be between 0 and 100")
+	}
+	return nil
+}
+
+// ValidateOwnedLabels checks if the given OwnedLabels is valid
+func ValidateOwnedLabels(ownedLabels v1beta1.OwnedLabels) error {
+	var errs []error
+	if ownedLabels.Spec.Domain == nil && ownedLabels.Spec.NamePattern == nil &&
+		ownedLabels.Spec.AnnotationKeyPattern == nil && ownedLabels.Spec.TaintKeyPattern == nil {
+		errs = append(errs, fmt.Errorf("at least one of domain, namePattern, annotationKeyPattern and taintKeyPattern must be set"))
+	}
+	if ownedLabels.Spec.Domain != nil {
+		if validationErrs := validation.IsDNS1123Subdomain(*ownedLabels.Spec.Domain); len(validationErrs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid domain %q: %s", *ownedLabels.Spec.Domain, strings.Join(validationErrs, "; ")))
+		}
+	}
+	if err := ValidateOwnedLabelsPattern(ownedLabels); err != nil {
+		errs = append(errs, err)
+	}
+	if ownedLabels.Spec.MaxRemovals != nil {
+		if err := ValidateMaxRemovals(ownedLabels.Spec.MaxRemovals); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if ownedLabels.Spec.MatchExpression != "" {
+		if _, err := CompileMatchExpression(ownedLabels.Spec.MatchExpression); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ValidateOwnedLabelsPattern checks if the name pattern and the annotation and taint key patterns of the given
+// OwnedLabels are valid
+func ValidateOwnedLabelsPattern(ownedLabels v1beta1.OwnedLabels) error {
+	if ownedLabels.Spec.NamePattern != nil {
+		if _, err := regexp.Compile(fmt.Sprintf("%s%s%s", "^", *ownedLabels.Spec.NamePattern, "$")); err != nil {
+			return fmt.Errorf("invalid name pattern %q: %v", *ownedLabels.Spec.NamePattern, err)
+		}
+	}
+	if ownedLabels.Spec.AnnotationKeyPattern != nil {
+		if _, err := regexp.Compile(fmt.Sprintf("%s%s%s", "^", *ownedLabels.Spec.AnnotationKeyPattern, "$")); err != nil {
+			return fmt.Errorf("invalid annotation key pattern %q: %v", *ownedLabels.Spec.AnnotationKeyPattern, err)
+		}
+	}
+	if ownedLabels.Spec.TaintKeyPattern != nil {
+		if _, err := regexp.Compile(fmt.Sprintf("%s%s%s", "^", *ownedLabels.Spec.TaintKeyPattern, "$")); err != nil {
+			return fmt.Errorf("invalid taint key pattern %q: %v", *ownedLabels.Spec.TaintKeyPattern, err)
+		}
+	}
+	return nil
+}
+
+// ValidateLabelMigration checks if the given LabelMigration is valid
+func ValidateLabelMigration(migration v1beta1.LabelMigration) error {
+	var errs []error
+	re, err := compileSourcePattern(migration.Spec.SourcePattern)
+	if migration.Spec.SourcePattern == "" {
+		errs = append(errs, fmt.Errorf("sourcePattern must be set"))
+	} else if err != nil {
+		errs = append(errs, fmt.Errorf("invalid source pattern %q: %v", migration.Spec.SourcePattern, err))
+	}
+	if err := validateLabel(migration.Spec.Target, ""); err != nil {
+		errs = append(errs, err)
+	} else if !strings.Contains(migration.Spec.Target, "/") {
+		errs = append(errs, fmt.Errorf("invalid label name %q: must be in domain/name format", migration.Spec.Target))
+	} else if re != nil && re.MatchString(migration.Spec.Target) {
+		errs = append(errs, fmt.Errorf("invalid target %q: must not match the source pattern", migration.Spec.Target))
+	}
+	for value, mapped := range migration.Spec.ValueMapping {
+		if err := validateLabel(migration.Spec.Target, mapped); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value mapping of %q: %v", value, err))
+		}
+	}
+	if migration.Spec.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(migration.Spec.NodeSelector); err != nil {
+			errs = append(errs, fmt.Errorf("invalid node selector: %v", err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateLabelTemplate checks if the given label name and value are valid, with capture group references
+// replaced by a placeholder. Label names need to be in domain/name format, otherwise they can't be owned.
+func validateLabelTemplate(name, value string) error {
+	expandedName := templateReference.ReplaceAllString(name, "x")
+	expandedValue := templateReference.ReplaceAllString(value, "x")
+	if err := validateLabel(expandedName, expandedValue); err != nil {
+		return err
+	}
+	if !strings.Contains(expandedName, "/") {
+		return fmt.Errorf("invalid label name %q: must be in domain/name format", name)
+	}
+	return nil
+}
+
+// validateDistributions checks if the distributed labels of the given Labels are valid
+func validateDistributions(labels v1beta1.Labels) error {
+	var errs []error
+	names := map[string]bool{}
+	for _, distribution := range labels.Spec.Distributions {
+		if names[distribution.Name] {
+			errs = append(errs, fmt.Errorf("invalid distribution: label %q is distributed twice", distribution.Name))
+		}
+		names[distribution.Name] = true
+		if _, exists := labels.Spec.Labels[distribution.Name]; exists {
+			errs = append(errs, fmt.Errorf("invalid distribution: label %q is also set in labels", distribution.Name))
+		}
+		if len(distribution.Values) == 0 {
+			errs = append(errs, fmt.Errorf("invalid distribution: label %q has no values", distribution.Name))
+		}
+		values := map[string]bool{}
+		for _, value := range distribution.Values {
+			if err := validateLabel(distribution.Name, value); err != nil {
+				errs = append(errs, err)
+			}
+			if values[value] {
+				errs = append(errs, fmt.Errorf("invalid distribution: label %q has value %q twice", distribution.Name, value))
+			}
+			values[value] = true
+		}
+		if !strings.Contains(distribution.Name, "/") {
+			errs = append(errs, fmt.Errorf("invalid label name %q: must be in domain/name format", distribution.Name))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateInventory checks if the given inventory source is valid
+func validateInventory(source *v1beta1.InventorySource) error {
+	if source == nil {
+		return nil
+	}
+	var errs []error
+	if validationErrs := validation.IsDNS1123Subdomain(source.ConfigMapName); len(validationErrs) > 0 {
+		errs = append(errs, fmt.Errorf("invalid inventory configMapName %q: %s", source.ConfigMapName, strings.Join(validationErrs, "; ")))
+	}
+	if validationErrs := validation.IsConfigMapKey(source.Key); len(validationErrs) > 0 {
+		errs = append(errs, fmt.Errorf("invalid inventory key %q: %s", source.Key, strings.Join(validationErrs, "; ")))
+	}
+	if source.Format != "csv" && source.Format != "yaml" {
+		errs = append(errs, fmt.Errorf("invalid inventory format %q: must be csv or yaml", source.Format))
+	}
+	if source.KeyColumn == "" {
+		errs = append(errs, fmt.Errorf("invalid inventory: keyColumn must be set"))
+	}
+	switch source.MatchBy {
+	case "", v1beta1.InventoryMatchByHostname, v1beta1.InventoryMatchByProviderID, v1beta1.InventoryMatchByAddress:
+	default:
+		errs = append(errs, fmt.Errorf("invalid inventory matchBy %q", source.MatchBy))
+	}
+	domain := strings.TrimSuffix(source.LabelPrefix, "/")
+	if !strings.HasSuffix(source.LabelPrefix, "/") || len(validation.IsDNS1123Subdomain(domain)) > 0 {
+		errs = append(errs, fmt.Errorf("invalid inventory labelPrefix %q: must be in domain/ format", source.LabelPrefix))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ValidateMaxRemovals validates the given removal limit, which must be a non negative number or percentage
+func ValidateMaxRemovals(maxRemovals *intstr.IntOrString) error {
+	value, err := intstr.GetValueFromIntOrPercent(maxRemovals, 100, false)
+	if err != nil {
+		return fmt.Errorf("invalid maxRemovals %q: %v", maxRemovals.String(), err)
+	}
+	if value < 0 {
+		return fmt.Errorf("invalid maxRemovals %q: must not be negative", maxRemovals.String())
+	}
+	return nil
+}
+
+// validateAnnotationTemplate checks if the given annotation name is valid, with capture group references
+// replaced by a placeholder
+func validateAnnotationTemplate(name, value string) error {
+	expandedName := templateReference.ReplaceAllString(name, "x")
+	expandedValue := templateReference.ReplaceAllString(value, "x")
+	return validateAnnotation(expandedName, expandedValue)
+}
+
+// validateTaint checks if the given taint has a valid key, value and effect
+func validateTaint(taint v1.Taint) error {
+	if errs := validation.IsQualifiedName(taint.Key); len(errs) > 0 {
+		return fmt.Errorf("invalid taint key %q: %s", taint.Key, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(taint.Value); len(errs) > 0 {
+		return fmt.Errorf("invalid value %q of taint %q: %s", taint.Value, taint.Key, strings.Join(errs, "; "))
+	}
+	switch taint.Effect {
+	case v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+		return nil
+	default:
+		return fmt.Errorf("invalid effect %q of taint %q", taint.Effect, taint.Key)
+	}
+}